@@ -0,0 +1,96 @@
+// catalog-import - імпорт прайс-листа постачальника (CSV/JSON) у каталог.
+//
+// Приклад:
+//
+//	go run ./cmd/catalog-import -file prices.csv -delimiter ";" -map "sku=Артикул,price=Ціна" -dry-run
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	_ "github.com/lib/pq"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/service"
+)
+
+func main() {
+	file := flag.String("file", "", "шлях до прайс-листа (CSV або JSON)")
+	format := flag.String("format", "", "формат файлу: csv або json (за замовчуванням - з розширення)")
+	delimiter := flag.String("delimiter", ",", "роздільник колонок CSV")
+	mapping := flag.String("map", "", "відповідність колонок, напр. sku=Артикул,price=Ціна,specs.socket=Сокет")
+	dryRun := flag.Bool("dry-run", false, "лише показати зміни, нічого не записувати")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "шлях до YAML-файлу конфігурації (або CONFIG_FILE)")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	columns, err := service.ParseColumnMapping(*mapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Не вдалося відкрити файл: %s", err)
+	}
+	defer f.Close()
+
+	// Токенів утиліта не видає - ключі JWT на машині, звідки запускають імпорт, не потрібні
+	cfg, err := config.Load(*configPath, config.WithoutAuth())
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := repository.NewPostgresDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("DB Error: %s", err.Error())
+	}
+	defer db.Close()
+
+	importService := service.NewCatalogImportService(repository.NewComponentRepository(db))
+
+	delim, _ := utf8.DecodeRuneInString(*delimiter)
+	report, err := importService.Import(context.Background(), f, service.ImportOptions{
+		Format:    *format,
+		Delimiter: delim,
+		Mapping:   columns,
+		DryRun:    *dryRun,
+	})
+
+	// Звіт друкуємо навіть при помилках валідації - там перелік проблемних рядків
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(report); encErr != nil {
+		log.Printf("Не вдалося вивести звіт: %s", encErr)
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrImportHasErrors) {
+			log.Printf("Імпорт скасовано: %d рядків з помилками", len(report.Errors))
+			os.Exit(1)
+		}
+		log.Fatalf("Помилка імпорту: %s", err)
+	}
+
+	if report.DryRun {
+		log.Printf("Dry-run: створити %d, оновити %d, без змін %d", report.Created, report.Updated, report.Unchanged)
+	} else {
+		log.Printf("Імпорт завершено: створено %d, оновлено %d, без змін %d", report.Created, report.Updated, report.Unchanged)
+	}
+}
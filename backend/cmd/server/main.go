@@ -2,80 +2,184 @@ package main
 
 import (
 	"context"
-	"log"
-	"net/http"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	_ "github.com/lib/pq"
+	"gopkg.in/yaml.v3"
 
 	"pc-configurator/config"
+	"pc-configurator/internal/app"
 	delivery "pc-configurator/internal/delivery/http"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/metrics"
 	"pc-configurator/internal/repository"
 	"pc-configurator/internal/service"
+	"pc-configurator/migrations"
 )
 
+// Використання:
+//
+//	server [-config config.yaml]                запуск API
+//	server [-config config.yaml] config print   показати підсумкову конфігурацію без секретів
+//	server [-config config.yaml] migrate        застосувати міграції схеми (перед запуском нової версії)
 func main() {
-	cfg := config.LoadConfig()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "шлях до YAML-файлу конфігурації (або CONFIG_FILE)")
+	flag.Parse()
+
+	if args := flag.Args(); len(args) > 0 {
+		var err error
+		switch {
+		case len(args) == 2 && args[0] == "config" && args[1] == "print":
+			err = printConfig(*configPath)
+		case len(args) == 1 && args[0] == "migrate":
+			err = migrate(*configPath)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", args)
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// --- ВИПРАВЛЕННЯ ТУТ: DSN без дужок () ---
-	db, err := repository.NewPostgresDB(cfg.Database.DSN)
+	// JSON-логи з першого рядка; рівень з конфігу застосовуємо після його читання
+	logLevel := new(slog.LevelVar)
+	slog.SetDefault(logging.New(os.Stdout, logLevel))
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatalf("DB Error: %s", err.Error())
+		slog.Error("configuration is invalid", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
-	log.Println("Connected to DB!")
+	logLevel.Set(cfg.Log.Level)
+	for _, warning := range cfg.Warnings() {
+		slog.Warn(warning)
+	}
+
+	db, err := repository.NewPostgresDB(cfg.Database)
+	if err != nil {
+		slog.Error("database connection failed", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("connected to database")
+	metrics.RegisterDBStats(db, "postgres")
 
 	// 1. Ініціалізація ВСІХ репозиторіїв
 	compRepo := repository.NewComponentRepository(db)
 	authRepo := repository.NewAuthPostgres(db)
 	orderRepo := repository.NewOrderRepo(db)
+	priceRepo := repository.NewPriceHistoryRepo(db)
+	watchRepo := repository.NewWatchRepo(db)
+
+	// Каталог для читання - з кешу в пам'яті (імпорт прайсу пише напряму, кеш дізнається з NOTIFY)
+	var catalog repository.ComponentRepository = compRepo
+	var catalogListener *repository.ComponentChangeListener
+	if cfg.CatalogCache.Enabled {
+		cache := repository.NewComponentCache(compRepo, cfg.CatalogCache.TTL)
+		catalog = cache
+		if cfg.CatalogCache.Listen {
+			catalogListener = repository.NewComponentChangeListener(cfg.Database.DSN, cache)
+		}
+	}
 
 	// 2. Сервіси
-	compService := service.NewCompatibilityService(compRepo)
-	authService := service.NewAuthService(authRepo)
-
-	// 3. Хендлери (Передаємо 4 аргументи!)
-	handlers := delivery.NewHandler(compRepo, compService, authService, orderRepo)
-	mw := delivery.NewMiddleware(authService)
+	compService := service.NewCompatibilityService(catalog)
+	jwtKeys, err := service.NewKeyRing(cfg.JWT)
+	if err != nil {
+		slog.Error("JWT keys are invalid", "error", err)
+		os.Exit(1)
+	}
+	mailer := service.NewMailer(cfg.Mail)
+	var loginAttempts repository.LoginAttempts = repository.NewLoginAttemptsPostgres(db)
+	if cfg.LoginAttemptsStore == "memory" {
+		loginAttempts = repository.NewLoginAttemptsMemory()
+	}
+	authService := service.NewAuthService(authRepo, jwtKeys, mailer, cfg.AppURL, service.NewLoginGuard(loginAttempts), cfg.JWT.AccessTTL, cfg.JWT.RefreshTTL)
+	importService := service.NewCatalogImportService(compRepo)
+	priceService := service.NewPriceHistoryService(catalog, priceRepo)
+	watchService := service.NewWatchService(watchRepo, catalog)
+	oidcService := service.NewOIDCService(authService, authRepo, jwtKeys, cfg.OIDC)
+	accountService := service.NewAccountService(authService, authRepo, orderRepo, watchRepo)
+	schemaVersion, err := migrations.Latest()
+	if err != nil {
+		slog.Error("embedded migrations are invalid", "error", err)
+		os.Exit(1)
+	}
+	healthService := service.NewHealthService(repository.NewHealthPostgres(db), schemaVersion)
 
-	// 4. Роутер
-	mux := http.NewServeMux()
+	// 3. Хендлери
+	handlers := delivery.NewHandler(catalog, compService, authService, orderRepo, importService, priceService, watchService, oidcService, accountService, healthService)
+	mw := delivery.NewMiddleware(authService, cfg.Server.TrustProxy, cfg.CORS, repository.NewRateLimiterMemory(), cfg.RateLimit)
 
-	mux.HandleFunc("/api/auth/sign-up", handlers.SignUp)
-	mux.HandleFunc("/api/auth/sign-in", handlers.SignIn)
-	mux.HandleFunc("/api/components", handlers.GetAllComponents)
-	mux.HandleFunc("/api/validate", handlers.ValidateBuild)
-	mux.HandleFunc("/api/recommend", handlers.GetRecommendation)
+	// 4. Роутер і middleware
+	router := delivery.NewRouter(handlers, mw, cfg.Features)
+	for _, route := range mw.UnusedRateLimits() {
+		slog.Warn("rate limit rule does not match any route", "route", route)
+	}
+	handler := mw.ClientIPMiddleware(mw.RequestLogMiddleware(mw.MetricsMiddleware(mw.CORSMiddleware(router))))
 
-	// НОВИЙ МАРШРУТ ДЛЯ ЗАМОВЛЕНЬ (лише для авторизованих користувачів)
-	mux.HandleFunc("/api/orders", mw.AuthMiddleware(handlers.CreateOrder))
+	// 5. Життєвий цикл: сервер, фонові задачі та пул БД зупиняються разом за сигналом
+	application := app.New(cfg.Server, handler, db, healthService)
+	if cfg.Features.PriceAlerts {
+		application.AddWorker("price_alerts", service.NewPriceAlertWorker(watchRepo, mailer, cfg.Alerts.Interval))
+	}
+	if catalogListener != nil {
+		application.AddWorker("catalog_listener", catalogListener)
+	}
 
-	// ЗАХИЩЕНІ МАРШРУТИ (потребують авторизації)
-	mux.HandleFunc("/api/auth/me", mw.AuthMiddleware(handlers.GetProfile))
-	mux.HandleFunc("/api/auth/change-password", mw.AuthMiddleware(handlers.ChangePassword))
-	mux.HandleFunc("/api/auth/update-profile", mw.AuthMiddleware(handlers.UpdateProfile))
-	mux.HandleFunc("/api/orders/my", mw.AuthMiddleware(handlers.GetUserOrders))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// 5. Запуск сервера
-	handlerWithCORS := mw.CORSMiddleware(mux)
+	if err := application.Run(ctx); err != nil {
+		os.Exit(1)
+	}
+}
 
-	srv := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: handlerWithCORS,
+// printConfig - виводить конфігурацію (файл + оточення) у YAML, секрети замасковано
+func printConfig(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
 	}
 
-	go func() {
-		log.Printf("Server started on port %s", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal(err)
-		}
-	}()
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// migrate - застосовує вбудовані міграції; без них /readyz лишається 503
+func migrate(path string) error {
+	// Токенів міграції не видають - ключі JWT для pre-deploy команди не потрібні
+	cfg, err := config.Load(path, config.WithoutAuth())
+	if err != nil {
+		return err
+	}
+	db, err := repository.NewPostgresDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
 
-	srv.Shutdown(context.Background())
+	applied, err := migrations.Up(context.Background(), db)
+	for _, v := range applied {
+		fmt.Printf("applied migration %d\n", v)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("schema is up to date")
+	}
+	return nil
 }
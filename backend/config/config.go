@@ -1,55 +1,564 @@
 package config
 
 import (
-	"log"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// Config - уся конфігурація сервера.
+// Джерела за зростанням пріоритету: значення за замовчуванням, YAML-файл, змінні оточення.
 type Config struct {
-	Database struct {
-		DSN string
+	Database DatabaseConfig `yaml:"database"`
+	Server   ServerConfig   `yaml:"server"`
+	CORS     CORSConfig     `yaml:"cors"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	Mail     MailConfig     `yaml:"mail"`
+	Alerts   struct {
+		// Як часто фоновий воркер перевіряє ціни відстежень
+		Interval time.Duration `yaml:"interval"`
+	} `yaml:"alerts"`
+	// AppURL - адреса фронтенду, на неї ведуть посилання з листів
+	AppURL string `yaml:"app_url"`
+	// LoginAttemptsStore - де зберігати лічильники невдалих входів: postgres або memory
+	LoginAttemptsStore string `yaml:"login_attempts_store"`
+	// OIDC - провайдери входу через OpenID Connect (Google, Keycloak ...)
+	OIDC         []OIDCProvider     `yaml:"oidc"`
+	Log          LogConfig          `yaml:"log"`
+	Features     FeatureFlags       `yaml:"features"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
+	CatalogCache CatalogCacheConfig `yaml:"catalog_cache"`
+
+	// warnings - не помилки, але варто побачити в лозі при старті
+	warnings []string
+}
+
+// DatabaseConfig - підключення до PostgreSQL і розмір пулу з'єднань
+type DatabaseConfig struct {
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// Скільки разів і з якою паузою пробувати підключитися при старті
+	ConnAttempts   int           `yaml:"conn_attempts"`
+	ConnRetryDelay time.Duration `yaml:"conn_retry_delay"`
+}
+
+// ServerConfig - HTTP-сервер і порядок його зупинки
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	// TrustProxy - сервер за реверс-проксі, IP клієнта беремо з X-Forwarded-For
+	TrustProxy bool `yaml:"trust_proxy"`
+
+	// Таймаути з'єднань: без них повільний клієнт тримає горутину і сокет скільки завгодно
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+
+	// DrainDelay - скільки /readyz віддає 503 перед зупинкою сервера,
+	// щоб балансувальник встиг прибрати інстанс
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// ShutdownTimeout - загальний ліміт на завершення запитів і фонових задач
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// CORSConfig - з яких origin-ів і як браузер може звертатися до API
+type CORSConfig struct {
+	// AllowedOrigins - точні origin-и ("https://shop.example.com"), шаблони піддоменів
+	// ("https://*.example.com") або "*" - будь-який origin (лише для розробки).
+	// Не задано - дозволено лише origin фронтенду з app_url.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// AllowCredentials - дозволити cookie/HTTP-автентифікацію; несумісно з "*"
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	// ExposedHeaders - заголовки відповіді, які може прочитати JS фронтенду
+	ExposedHeaders []string `yaml:"exposed_headers"`
+	// MaxAge - скільки браузер кешує відповідь на preflight
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// LogConfig - налаштування логування
+type LogConfig struct {
+	// Level - мінімальний рівень логів: debug, info, warn або error
+	Level slog.Level `yaml:"level"`
+}
+
+// FeatureFlags - вмикання/вимикання окремих частин сервера без перезбирання
+type FeatureFlags struct {
+	// PriceAlerts - фоновий воркер сповіщень про зниження цін
+	PriceAlerts bool `yaml:"price_alerts"`
+	// Metrics - ендпоінт /metrics для Prometheus
+	Metrics bool `yaml:"metrics"`
+}
+
+// CatalogCacheConfig - каталог компонентів у пам'яті сервера
+type CatalogCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTL - найдовше, скільки кеш може не помічати зміни (напр. якщо LISTEN недоступний)
+	TTL time.Duration `yaml:"ttl"`
+	// Listen - скидати кеш одразу за NOTIFY components_changed з PostgreSQL
+	Listen bool `yaml:"listen"`
+}
+
+// RateLimitConfig - обмеження частоти запитів до окремих маршрутів
+type RateLimitConfig struct {
+	Enabled bool            `yaml:"enabled"`
+	Routes  []RateLimitRule `yaml:"routes"`
+}
+
+// RateLimitRule - token bucket для одного маршруту: до Requests запитів поспіль,
+// далі відновлюється Requests запитів за Per
+type RateLimitRule struct {
+	// Route - шаблон маршруту так, як його зареєстровано: "POST /api/recommend"
+	Route    string        `yaml:"route"`
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	// Key - кого обмежуємо: "ip" або "user" (анонімні запити - за IP)
+	Key string `yaml:"key"`
+}
+
+// OIDCProvider - налаштування одного провайдера OpenID Connect.
+// Endpoint-и та ключі беруться з IssuerURL/.well-known/openid-configuration.
+type OIDCProvider struct {
+	Name         string   `yaml:"name"` // Використовується в URL: /api/auth/oidc/{name}/start
+	IssuerURL    string   `yaml:"issuer_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"` // Має вести на /api/auth/oidc/{name}/callback цього сервера
+	Scopes       []string `yaml:"scopes"`
+}
+
+// MailConfig - відправка листів: "smtp" або "log" (у лог/файл, для розробки)
+type MailConfig struct {
+	Driver   string `yaml:"driver"`
+	From     string `yaml:"from"`
+	LogFile  string `yaml:"log_file"` // Для driver=log: порожньо - у стандартний лог
+	SMTPHost string `yaml:"smtp_host"`
+	SMTPPort string `yaml:"smtp_port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// JWTConfig - ключі підпису токенів і час їх життя.
+// Підписуємо ключем SigningKeyID, а перевіряємо будь-яким з Keys (за заголовком kid),
+// тож старий ключ можна тримати у списку, доки не протухнуть видані ним токени.
+type JWTConfig struct {
+	SigningKeyID string   `yaml:"signing_key_id"`
+	Keys         []JWTKey `yaml:"keys"`
+	// Access-токен короткий: відкликати його неможливо, тому він швидко протухає
+	AccessTTL time.Duration `yaml:"access_ttl"`
+	// Refresh-токен живе довго, але ротується при кожному використанні
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+	// GenerateKey - лише для локальної розробки: якщо ключі не задані, процес генерує
+	// випадковий HS256-ключ. Токени не переживуть перезапуску і не діють між інстансами.
+	GenerateKey bool `yaml:"generate_key"`
+}
+
+// JWTKey - один ключ: HS256 (Secret) або RS256/EdDSA (PEM-файли)
+type JWTKey struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"` // HS256, RS256 або EdDSA
+	Secret         string `yaml:"secret"`
+	PrivateKeyFile string `yaml:"private_key_file"` // Потрібен лише ключу, яким підписуємо
+	PublicKeyFile  string `yaml:"public_key_file"`  // Можна не вказувати, якщо є приватний ключ
+}
+
+// DefaultJWTKeyID - ключ для токенів без заголовка kid (видані до ротації ключів)
+const DefaultJWTKeyID = "default"
+
+// minHS256SecretLen - RFC 7518: ключ HS256 не коротший за вихід SHA-256 (32 байти)
+const minHS256SecretLen = 32
+
+// Відомі issuer-и, щоб для них не треба було задавати OIDC_<NAME>_ISSUER
+var knownOIDCIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+}
+
+// Default - конфігурація за замовчуванням (для локальної розробки вистачає DATABASE_URL)
+func Default() *Config {
+	cfg := &Config{
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnAttempts:    3,
+			ConnRetryDelay:  time.Second,
+		},
+		Server: ServerConfig{
+			Port:              "8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			// Імпорт прайс-листа до 10 МБ - даємо запасу на читання тіла і обробку
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "Retry-After", "Deprecation", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+			MaxAge:         10 * time.Minute,
+		},
+		JWT: JWTConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			From:     "PC Configurator <no-reply@localhost>",
+			SMTPPort: "587",
+		},
+		AppURL:             "http://localhost:3000",
+		LoginAttemptsStore: "postgres",
+		Log:                LogConfig{Level: slog.LevelInfo},
+		Features:           FeatureFlags{PriceAlerts: true, Metrics: true},
+		CatalogCache:       CatalogCacheConfig{Enabled: true, TTL: 5 * time.Minute, Listen: true},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Routes: []RateLimitRule{
+				// Підбір паролів і масові реєстрації
+				{Route: "POST /api/auth/sign-in", Requests: 10, Per: time.Minute, Key: "ip"},
+				{Route: "POST /api/auth/sign-in/2fa", Requests: 10, Per: time.Minute, Key: "ip"},
+				{Route: "POST /api/auth/sign-up", Requests: 10, Per: time.Hour, Key: "ip"},
+				{Route: "POST /api/auth/password-reset/request", Requests: 5, Per: time.Hour, Key: "ip"},
+				// Підбір збірки завантажує весь каталог
+				{Route: "POST /api/recommend", Requests: 20, Per: time.Minute, Key: "user"},
+			},
+		},
+	}
+	// Перевірка цін для сповіщень - раз на 15 хвилин
+	cfg.Alerts.Interval = 15 * time.Minute
+	return cfg
+}
+
+// LoadOption - налаштовує Load
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	withoutAuth bool
+}
+
+// WithoutAuth - для утиліт, які не видають і не перевіряють токени (імпорт прайсу, міграції):
+// ключі JWT не перевіряються і не генеруються, тож на машині, звідки їх запускають,
+// секрети сервера не потрібні
+func WithoutAuth() LoadOption {
+	return func(o *loadOptions) { o.withoutAuth = true }
+}
+
+// Load - читає конфігурацію: значення за замовчуванням, потім YAML-файл path
+// (порожній - без файлу), потім змінні оточення (і .env, якщо він є).
+// Усі знайдені проблеми повертаються разом однією помилкою.
+func Load(path string, opts ...LoadOption) (*Config, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
-	Server struct {
-		Port string
+
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
 	}
+
+	// .env не обов'язковий (напр. на Render змінні задає платформа)
+	_ = godotenv.Load()
+
+	envErr := cfg.applyEnv()
+	cfg.normalize()
+	if err := errors.Join(envErr, cfg.validate(!o.withoutAuth)); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	if len(cfg.JWT.Keys) == 0 && cfg.JWT.GenerateKey && !o.withoutAuth {
+		secret := make([]byte, minHS256SecretLen)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate jwt key: %w", err)
+		}
+		cfg.warnings = append(cfg.warnings, "JWT keys are not set, using a generated key: sessions end on restart")
+		cfg.JWT.Keys = []JWTKey{{ID: DefaultJWTKeyID, Algorithm: "HS256", Secret: hex.EncodeToString(secret)}}
+		cfg.JWT.SigningKeyID = DefaultJWTKeyID
+	}
+
+	return cfg, nil
 }
 
-func LoadConfig() *Config {
-	// Спробуємо завантажити .env файл.
-	// Якщо його немає (наприклад, на Render), це не помилка,
-	// ми просто будемо читати змінні системи.
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+// loadFile - YAML з тими ж ключами, що й теги структур; невідомий ключ - помилка,
+// щоб одруківка не перетворювалась на мовчазне значення за замовчуванням
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
 	}
 
-	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
 
-	// 1. Читаємо URL бази даних
-	cfg.Database.DSN = os.Getenv("DATABASE_URL")
-	if cfg.Database.DSN == "" {
-		log.Fatal("DATABASE_URL is not set")
+// normalize - доповнює значення, які залежать від інших полів
+func (c *Config) normalize() {
+	c.AppURL = strings.TrimRight(c.AppURL, "/")
+	// Як і database/sql: простоюючих з'єднань не більше, ніж відкритих
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		c.Database.MaxIdleConns = c.Database.MaxOpenConns
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		if u, err := url.Parse(c.AppURL); err == nil && u.Scheme != "" && u.Host != "" {
+			c.CORS.AllowedOrigins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+	for i, origin := range c.CORS.AllowedOrigins {
+		c.CORS.AllowedOrigins[i] = strings.ToLower(strings.TrimRight(origin, "/"))
+	}
+	for i, method := range c.CORS.AllowedMethods {
+		c.CORS.AllowedMethods[i] = strings.ToUpper(method)
 	}
 
-	// 2. Читаємо Порт (або ставимо 8080 за замовчуванням)
-	cfg.Server.Port = os.Getenv("PORT")
-	if cfg.Server.Port == "" {
-		cfg.Server.Port = "8080"
+	if c.JWT.SigningKeyID == "" && len(c.JWT.Keys) > 0 {
+		c.JWT.SigningKeyID = c.JWT.Keys[len(c.JWT.Keys)-1].ID
+	}
+	for i := range c.JWT.Keys {
+		if c.JWT.Keys[i].Algorithm == "" {
+			c.JWT.Keys[i].Algorithm = "HS256"
+		}
 	}
 
-	return cfg
+	for i := range c.OIDC {
+		p := &c.OIDC[i]
+		p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+		if p.IssuerURL == "" {
+			p.IssuerURL = knownOIDCIssuers[p.Name]
+		}
+		p.IssuerURL = strings.TrimRight(p.IssuerURL, "/")
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+	}
 }
 
-// Додатковий метод для DSN, щоб не ламати старий код
-func (c *Config) Database_DSN() string {
-	return c.Database.DSN
+// Warnings - зауваження до конфігурації, які не заважають запуску
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// Validate - перевіряє зведену конфігурацію і повертає всі проблеми разом
+func (c *Config) Validate() error {
+	return c.validate(true)
 }
 
-// Додатковий метод для DSN, якщо у тебе в main.go викликається c.Database.DSN()
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// validate - auth=false пропускає ключі JWT (див. WithoutAuth)
+func (c *Config) validate(auth bool) error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Database.DSN == "" {
+		fail("database.dsn (DATABASE_URL) is not set")
 	}
-	return fallback
+	if c.Database.MaxOpenConns < 1 {
+		fail("database.max_open_conns must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		fail("database.max_idle_conns must not be negative")
+	}
+	if c.Database.ConnAttempts < 1 {
+		fail("database.conn_attempts must be at least 1")
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		fail("server.port must be a number from 1 to 65535, got %q", c.Server.Port)
+	}
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"database.conn_max_lifetime", c.Database.ConnMaxLifetime},
+		{"database.conn_retry_delay", c.Database.ConnRetryDelay},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.drain_delay", c.Server.DrainDelay},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			fail("%s must not be negative", d.name)
+		}
+	}
+	// Нуль тут означав би "одразу" або "ніколи" - обидва варіанти не мають сенсу
+	positive := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"alerts.interval", c.Alerts.Interval},
+		{"catalog_cache.ttl", c.CatalogCache.TTL},
+		{"jwt.access_ttl", c.JWT.AccessTTL},
+		{"jwt.refresh_ttl", c.JWT.RefreshTTL},
+	}
+	for _, d := range positive {
+		if d.d <= 0 {
+			fail("%s must be greater than 0", d.name)
+		}
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				fail("cors.allowed_origins: \"*\" can not be used with allow_credentials")
+			}
+			continue
+		}
+		// Шаблон дозволяє "*" лише першою міткою хоста: https://*.example.com
+		host := origin
+		if scheme, rest, ok := strings.Cut(origin, "://"); ok && strings.HasPrefix(rest, "*.") {
+			host = scheme + "://wildcard" + strings.TrimPrefix(rest, "*")
+		}
+		u, err := url.Parse(host)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || strings.Contains(host, "*") {
+			fail("cors.allowed_origins: %q is not an origin like https://example.com or https://*.example.com", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		fail("cors.max_age must not be negative")
+	}
+
+	if u, err := url.Parse(c.AppURL); err != nil || u.Scheme == "" || u.Host == "" {
+		fail("app_url must be an absolute URL, got %q", c.AppURL)
+	}
+
+	if c.LoginAttemptsStore != "postgres" && c.LoginAttemptsStore != "memory" {
+		fail("login_attempts_store must be postgres or memory, got %q", c.LoginAttemptsStore)
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			fail("mail.smtp_host (SMTP_HOST) is not set for mail driver smtp")
+		}
+	default:
+		fail("mail.driver must be smtp or log, got %q", c.Mail.Driver)
+	}
+
+	seenRoutes := map[string]bool{}
+	for _, rule := range c.RateLimit.Routes {
+		method, path, ok := strings.Cut(rule.Route, " ")
+		switch {
+		case !ok || method == "" || !strings.HasPrefix(path, "/"):
+			fail("rate_limit.routes: %q is not a route like \"POST /api/recommend\"", rule.Route)
+		case seenRoutes[rule.Route]:
+			fail("rate_limit.routes: duplicate route %q", rule.Route)
+		case rule.Requests < 1:
+			fail("rate_limit.routes: %s: requests must be at least 1", rule.Route)
+		case rule.Per <= 0:
+			fail("rate_limit.routes: %s: per must be greater than 0", rule.Route)
+		case rule.Key != "ip" && rule.Key != "user":
+			fail("rate_limit.routes: %s: key must be ip or user, got %q", rule.Route, rule.Key)
+		}
+		seenRoutes[rule.Route] = true
+	}
+
+	if auth {
+		if err := c.JWT.validate(); err != nil {
+			fail("jwt: %w", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, p := range c.OIDC {
+		prefix := "oidc." + p.Name
+		switch {
+		case p.Name == "":
+			fail("oidc: provider without name")
+		case seen[p.Name]:
+			fail("oidc: duplicate provider %q", p.Name)
+		case p.IssuerURL == "":
+			fail("%s: issuer_url is not set", prefix)
+		case p.ClientID == "":
+			fail("%s: client_id is not set", prefix)
+		case p.RedirectURL == "":
+			fail("%s: redirect_url is not set", prefix)
+		}
+		seen[p.Name] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate - ключі без дублікатів, з потрібними секретами/файлами, ключ підпису є у списку.
+// Порожній список допустимий лише з generate_key: тоді Load генерує ключ для розробки.
+func (jc JWTConfig) validate() error {
+	if len(jc.Keys) == 0 {
+		if jc.SigningKeyID != "" {
+			return fmt.Errorf("signing key %q is set, but there are no keys", jc.SigningKeyID)
+		}
+		if jc.GenerateKey {
+			return nil
+		}
+		return fmt.Errorf("no signing keys: set JWT_SECRET or JWT_KEYS (jwt.generate_key=true for local development)")
+	}
+
+	seen := map[string]bool{}
+	for _, k := range jc.Keys {
+		if k.ID == "" {
+			return fmt.Errorf("key without id")
+		}
+		if seen[k.ID] {
+			return fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		seen[k.ID] = true
+
+		switch k.Algorithm {
+		case "HS256":
+			if k.Secret == "" {
+				return fmt.Errorf("HS256 key %q has no secret", k.ID)
+			}
+			if len(k.Secret) < minHS256SecretLen {
+				return fmt.Errorf("HS256 key %q is too short: need at least %d bytes", k.ID, minHS256SecretLen)
+			}
+		case "RS256", "EdDSA":
+			if k.PrivateKeyFile == "" && k.PublicKeyFile == "" {
+				return fmt.Errorf("%s key %q needs private or public key file", k.Algorithm, k.ID)
+			}
+		default:
+			return fmt.Errorf("unsupported algorithm %q for key %q", k.Algorithm, k.ID)
+		}
+	}
+
+	for _, k := range jc.Keys {
+		if k.ID == jc.SigningKeyID {
+			if k.Algorithm != "HS256" && k.PrivateKeyFile == "" {
+				return fmt.Errorf("signing key %q has no private key file", k.ID)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("signing key %q is not in the key list", jc.SigningKeyID)
+}
+
+// Додатковий метод для DSN, щоб не ламати старий код
+func (c *Config) Database_DSN() string {
+	return c.Database.DSN
 }
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configEnv - усі змінні, які читає applyEnv (OIDC_<NAME>_* - лише для провайдерів з OIDC_PROVIDERS)
+var configEnv = []string{
+	"DATABASE_URL", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_ATTEMPTS", "DB_CONN_RETRY_DELAY",
+	"PORT", "TRUST_PROXY", "SERVER_READ_HEADER_TIMEOUT", "SERVER_READ_TIMEOUT", "SERVER_WRITE_TIMEOUT", "SERVER_IDLE_TIMEOUT",
+	"SHUTDOWN_DRAIN_DELAY", "SHUTDOWN_TIMEOUT",
+	"CORS_ALLOWED_ORIGINS", "CORS_ALLOW_CREDENTIALS", "CORS_ALLOWED_METHODS", "CORS_ALLOWED_HEADERS", "CORS_EXPOSED_HEADERS", "CORS_MAX_AGE",
+	"LOG_LEVEL", "FEATURE_PRICE_ALERTS", "FEATURE_METRICS", "RATE_LIMIT_ENABLED", "RATE_LIMIT_ROUTES",
+	"CATALOG_CACHE_ENABLED", "CATALOG_CACHE_TTL", "CATALOG_CACHE_LISTEN", "LOGIN_ATTEMPTS_STORE", "PRICE_ALERT_INTERVAL",
+	"APP_URL", "MAIL_DRIVER", "MAIL_FROM", "EMAIL_LOG_FILE", "SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD",
+	"JWT_KEYS", "JWT_SECRET", "JWT_SIGNING_KEY_ID", "JWT_ACCESS_TTL", "JWT_REFRESH_TTL", "JWT_GENERATE_KEY",
+	"OIDC_PROVIDERS",
+}
+
+var testSecret = strings.Repeat("k", minHS256SecretLen)
+
+// setEnv - оточення тесту: змінні машини не впливають (порожня = не задана), потім env
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, key := range configEnv {
+		t.Setenv(key, "")
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// writeConfig - YAML-файл конфігурації у тимчасовій теці
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc", "JWT_SECRET": testSecret})
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != "8080" || cfg.LoginAttemptsStore != "postgres" || !cfg.CatalogCache.Enabled {
+		t.Errorf("defaults are not applied: %+v", cfg)
+	}
+	if len(cfg.JWT.Keys) != 1 || cfg.JWT.SigningKeyID != DefaultJWTKeyID || cfg.JWT.Keys[0].Algorithm != "HS256" {
+		t.Errorf("JWT_SECRET: %+v", cfg.JWT)
+	}
+	// Без явного списку CORS дозволено лише фронтенд
+	if !slices.Equal(cfg.CORS.AllowedOrigins, []string{"http://localhost:3000"}) {
+		t.Errorf("default CORS origins = %v", cfg.CORS.AllowedOrigins)
+	}
+	if len(cfg.Warnings()) != 0 {
+		t.Errorf("warnings = %v", cfg.Warnings())
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	file := writeConfig(t, `
+database:
+  dsn: postgres://file/pc
+  max_open_conns: 10
+  max_idle_conns: 20
+server:
+  port: "9000"
+  write_timeout: 90s
+app_url: https://shop.example.com/app/
+jwt:
+  keys:
+    - id: file
+      secret: `+testSecret+`
+rate_limit:
+  routes:
+    - route: POST /api/recommend
+      requests: 5
+      per: 1m
+      key: user
+`)
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "file over defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Database.DSN != "postgres://file/pc" || cfg.Server.Port != "9000" || cfg.Server.WriteTimeout != 90*time.Second {
+					t.Errorf("file values: %+v %+v", cfg.Database, cfg.Server)
+				}
+				if cfg.Server.ReadTimeout != 30*time.Second {
+					t.Errorf("default kept for a key missing in the file: read_timeout = %v", cfg.Server.ReadTimeout)
+				}
+				if cfg.Database.MaxIdleConns != 10 {
+					t.Errorf("max_idle_conns = %d, want capped to max_open_conns", cfg.Database.MaxIdleConns)
+				}
+				if cfg.AppURL != "https://shop.example.com/app" {
+					t.Errorf("app_url = %q", cfg.AppURL)
+				}
+				if !slices.Equal(cfg.CORS.AllowedOrigins, []string{"https://shop.example.com"}) {
+					t.Errorf("CORS origins from app_url = %v", cfg.CORS.AllowedOrigins)
+				}
+				if cfg.JWT.SigningKeyID != "file" {
+					t.Errorf("signing key = %q", cfg.JWT.SigningKeyID)
+				}
+				if len(cfg.RateLimit.Routes) != 1 || cfg.RateLimit.Routes[0].Requests != 5 {
+					t.Errorf("rate limit routes = %+v", cfg.RateLimit.Routes)
+				}
+			},
+		},
+		{
+			name: "env over file",
+			env: map[string]string{
+				"DATABASE_URL":         "postgres://env/pc",
+				"PORT":                 "9100",
+				"SERVER_WRITE_TIMEOUT": "2m",
+				"CORS_ALLOWED_ORIGINS": "https://A.example.com/, https://*.example.org",
+				"JWT_KEYS":             "kid=old,secret=" + testSecret + ";kid=new,secret=" + testSecret,
+				"RATE_LIMIT_ROUTES":    "POST /api/auth/sign-in=3/1m/ip",
+				"FEATURE_METRICS":      "false",
+				"LOG_LEVEL":            "debug",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Database.DSN != "postgres://env/pc" || cfg.Server.Port != "9100" || cfg.Server.WriteTimeout != 2*time.Minute {
+					t.Errorf("env values: %+v %+v", cfg.Database, cfg.Server)
+				}
+				if !slices.Equal(cfg.CORS.AllowedOrigins, []string{"https://a.example.com", "https://*.example.org"}) {
+					t.Errorf("CORS origins = %v", cfg.CORS.AllowedOrigins)
+				}
+				// Ключі з оточення замінюють файлові, підписуємо останнім
+				if len(cfg.JWT.Keys) != 2 || cfg.JWT.SigningKeyID != "new" {
+					t.Errorf("JWT_KEYS: %+v", cfg.JWT)
+				}
+				if len(cfg.RateLimit.Routes) != 1 || cfg.RateLimit.Routes[0].Route != "POST /api/auth/sign-in" {
+					t.Errorf("RATE_LIMIT_ROUTES: %+v", cfg.RateLimit.Routes)
+				}
+				if cfg.Features.Metrics || cfg.Log.Level.String() != "DEBUG" {
+					t.Errorf("features %+v, log level %v", cfg.Features, cfg.Log.Level)
+				}
+			},
+		},
+		{
+			name: "signing key from env",
+			env:  map[string]string{"JWT_KEYS": "kid=a,secret=" + testSecret + ";kid=b,secret=" + testSecret, "JWT_SIGNING_KEY_ID": "a"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.JWT.SigningKeyID != "a" {
+					t.Errorf("signing key = %q", cfg.JWT.SigningKeyID)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+			cfg, err := Load(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc", "JWT_SECRET": testSecret})
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "server:\n  prot: \"9000\"\n", "field prot not found"},
+		{"unknown section", "sever:\n  port: \"9000\"\n", "field sever not found"},
+		{"wrong type", "server:\n  read_timeout: fast\n", "into time.Duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("missing file accepted")
+	}
+	if _, err := Load(writeConfig(t, "")); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestLoadJoinsErrors(t *testing.T) {
+	setEnv(t, map[string]string{
+		"PORT":                 "http",
+		"DB_MAX_OPEN_CONNS":    "many",
+		"SHUTDOWN_TIMEOUT":     "0s",
+		"CORS_ALLOWED_ORIGINS": "https://*.*.example.com",
+		"MAIL_DRIVER":          "pigeon",
+		"RATE_LIMIT_ROUTES":    "POST /api/recommend=0/1m/ip",
+		"JWT_SECRET":           "short",
+	})
+
+	_, err := Load("")
+	if err == nil {
+		t.Fatal("invalid config accepted")
+	}
+
+	// Помилки розбору оточення і перевірки значень - однією помилкою, кожна окремим рядком
+	for _, want := range []string{
+		"DB_MAX_OPEN_CONNS is invalid",
+		"database.dsn (DATABASE_URL) is not set",
+		`server.port must be a number from 1 to 65535, got "http"`,
+		"server.shutdown_timeout must be greater than 0",
+		`cors.allowed_origins: "https://*.*.example.com" is not an origin`,
+		`mail.driver must be smtp or log, got "pigeon"`,
+		"rate_limit.routes: POST /api/recommend: requests must be at least 1",
+		`jwt: HS256 key "default" is too short`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+	if lines := strings.Count(err.Error(), "\n"); lines < 8 {
+		t.Errorf("errors are not joined line by line:\n%v", err)
+	}
+}
+
+func TestLoadJWTGenerateKey(t *testing.T) {
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc"})
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "no signing keys") {
+		t.Fatalf("no keys: err = %v", err)
+	}
+
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc", "JWT_GENERATE_KEY": "true"})
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.JWT.Keys) != 1 || len(cfg.JWT.Keys[0].Secret) < minHS256SecretLen || cfg.JWT.SigningKeyID != DefaultJWTKeyID {
+		t.Errorf("generated key: %+v", cfg.JWT)
+	}
+	if len(cfg.Warnings()) != 1 {
+		t.Errorf("warnings = %v", cfg.Warnings())
+	}
+
+	again, _ := Load("")
+	if again.JWT.Keys[0].Secret == cfg.JWT.Keys[0].Secret {
+		t.Error("generated key is not random")
+	}
+}
+
+func TestLoadWithoutAuth(t *testing.T) {
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc", "JWT_SECRET": "short"})
+
+	if _, err := Load(""); err == nil {
+		t.Fatal("short JWT secret accepted")
+	}
+
+	// Утиліти без токенів не залежать від ключів, але решта конфігурації перевіряється
+	cfg, err := Load("", WithoutAuth())
+	if err != nil {
+		t.Fatalf("WithoutAuth: %v", err)
+	}
+	if len(cfg.Warnings()) != 0 {
+		t.Errorf("warnings = %v", cfg.Warnings())
+	}
+
+	setEnv(t, map[string]string{"DATABASE_URL": "postgres://localhost/pc", "JWT_GENERATE_KEY": "true"})
+	if cfg, _ := Load("", WithoutAuth()); len(cfg.JWT.Keys) != 0 {
+		t.Errorf("key generated without auth: %+v", cfg.JWT)
+	}
+
+	setEnv(t, map[string]string{"PORT": "http"})
+	if _, err := Load("", WithoutAuth()); err == nil || !strings.Contains(err.Error(), "database.dsn") {
+		t.Errorf("err = %v, want the rest of the config validated", err)
+	}
+}
+
+func TestJWTConfigValidate(t *testing.T) {
+	hs := func(id string) JWTKey { return JWTKey{ID: id, Algorithm: "HS256", Secret: testSecret} }
+
+	tests := []struct {
+		name string
+		cfg  JWTConfig
+		want string // Порожньо - конфігурація коректна
+	}{
+		{"single key", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{hs("a")}}, ""},
+		{"rotation", JWTConfig{SigningKeyID: "b", Keys: []JWTKey{hs("a"), hs("b")}}, ""},
+		{"generated", JWTConfig{GenerateKey: true}, ""},
+		{"no keys", JWTConfig{}, "no signing keys"},
+		{"signing key without keys", JWTConfig{SigningKeyID: "a", GenerateKey: true}, "there are no keys"},
+		{"duplicate id", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{hs("a"), hs("a")}}, "duplicate key id"},
+		{"no id", JWTConfig{Keys: []JWTKey{hs("")}}, "key without id"},
+		{"short secret", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{{ID: "a", Algorithm: "HS256", Secret: "secret"}}}, "too short"},
+		{"unknown algorithm", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{{ID: "a", Algorithm: "none"}}}, "unsupported algorithm"},
+		{"RS256 without files", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{{ID: "a", Algorithm: "RS256"}}}, "needs private or public key file"},
+		{"sign with public key only", JWTConfig{SigningKeyID: "a", Keys: []JWTKey{{ID: "a", Algorithm: "RS256", PublicKeyFile: "a.pub"}}}, "has no private key file"},
+		{"verify only with public key", JWTConfig{SigningKeyID: "b", Keys: []JWTKey{{ID: "a", Algorithm: "EdDSA", PublicKeyFile: "a.pub"}, hs("b")}}, ""},
+		{"signing key not in list", JWTConfig{SigningKeyID: "c", Keys: []JWTKey{hs("a")}}, `signing key "c" is not in the key list`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv - перекриває значення з файлу змінними оточення.
+// Порожня змінна вважається незаданою; помилки розбору збираються, а не обривають читання.
+func (c *Config) applyEnv() error {
+	env := &envReader{}
+
+	env.str("DATABASE_URL", &c.Database.DSN)
+	env.integer("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	env.integer("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	env.duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	env.integer("DB_CONN_ATTEMPTS", &c.Database.ConnAttempts)
+	env.duration("DB_CONN_RETRY_DELAY", &c.Database.ConnRetryDelay)
+
+	env.str("PORT", &c.Server.Port)
+	env.boolean("TRUST_PROXY", &c.Server.TrustProxy)
+	env.duration("SERVER_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout)
+	env.duration("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	env.duration("SHUTDOWN_DRAIN_DELAY", &c.Server.DrainDelay)
+	env.duration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+
+	env.list("CORS_ALLOWED_ORIGINS", ",", &c.CORS.AllowedOrigins)
+	env.boolean("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	env.list("CORS_ALLOWED_METHODS", ",", &c.CORS.AllowedMethods)
+	env.list("CORS_ALLOWED_HEADERS", ",", &c.CORS.AllowedHeaders)
+	env.list("CORS_EXPOSED_HEADERS", ",", &c.CORS.ExposedHeaders)
+	env.duration("CORS_MAX_AGE", &c.CORS.MaxAge)
+
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if err := c.Log.Level.UnmarshalText([]byte(v)); err != nil {
+			env.fail("LOG_LEVEL", v)
+		}
+	}
+	env.boolean("FEATURE_PRICE_ALERTS", &c.Features.PriceAlerts)
+	env.boolean("FEATURE_METRICS", &c.Features.Metrics)
+
+	// Обмеження частоти (список з оточення замінює список з файлу):
+	//
+	//	RATE_LIMIT_ROUTES="POST /api/recommend=20/1m/user;POST /api/auth/sign-in=10/1m/ip"
+	env.boolean("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled)
+	if raw := os.Getenv("RATE_LIMIT_ROUTES"); raw != "" {
+		rules, err := parseRateLimitRules(raw)
+		if err != nil {
+			env.errs = append(env.errs, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err))
+		}
+		c.RateLimit.Routes = rules
+	}
+
+	env.boolean("CATALOG_CACHE_ENABLED", &c.CatalogCache.Enabled)
+	env.duration("CATALOG_CACHE_TTL", &c.CatalogCache.TTL)
+	env.boolean("CATALOG_CACHE_LISTEN", &c.CatalogCache.Listen)
+
+	env.str("LOGIN_ATTEMPTS_STORE", &c.LoginAttemptsStore)
+	env.duration("PRICE_ALERT_INTERVAL", &c.Alerts.Interval)
+
+	env.str("APP_URL", &c.AppURL)
+	env.str("MAIL_DRIVER", &c.Mail.Driver)
+	env.str("MAIL_FROM", &c.Mail.From)
+	env.str("EMAIL_LOG_FILE", &c.Mail.LogFile)
+	env.str("SMTP_HOST", &c.Mail.SMTPHost)
+	env.str("SMTP_PORT", &c.Mail.SMTPPort)
+	env.str("SMTP_USERNAME", &c.Mail.Username)
+	env.str("SMTP_PASSWORD", &c.Mail.Password)
+
+	// Ключі підпису JWT:
+	//
+	//	JWT_SECRET=...                      один HS256-ключ з id "default"
+	//	JWT_KEYS="kid=2025,alg=HS256,secret=...;kid=2026,alg=RS256,private=/keys/2026.pem"
+	//	JWT_SIGNING_KEY_ID=2026             яким ключем підписувати (за замовчуванням - останній у списку)
+	//	JWT_GENERATE_KEY=true               без ключів - випадковий ключ процесу (лише для розробки)
+	if raw := os.Getenv("JWT_KEYS"); raw != "" {
+		keys, err := parseJWTKeys(raw)
+		if err != nil {
+			env.errs = append(env.errs, fmt.Errorf("JWT_KEYS: %w", err))
+		}
+		c.JWT.Keys = keys
+		c.JWT.SigningKeyID = ""
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		c.JWT.Keys = []JWTKey{{ID: DefaultJWTKeyID, Algorithm: "HS256", Secret: secret}}
+		c.JWT.SigningKeyID = ""
+	}
+	env.str("JWT_SIGNING_KEY_ID", &c.JWT.SigningKeyID)
+	env.duration("JWT_ACCESS_TTL", &c.JWT.AccessTTL)
+	env.duration("JWT_REFRESH_TTL", &c.JWT.RefreshTTL)
+	env.boolean("JWT_GENERATE_KEY", &c.JWT.GenerateKey)
+
+	// Вхід через зовнішніх провайдерів (список з оточення замінює список з файлу)
+	if raw := os.Getenv("OIDC_PROVIDERS"); raw != "" {
+		c.OIDC = loadOIDCProviders(raw)
+	}
+
+	return errors.Join(env.errs...)
+}
+
+// loadOIDCProviders - читає провайдерів з оточення:
+//
+//	OIDC_PROVIDERS=google,keycloak
+//	OIDC_GOOGLE_CLIENT_ID=...  OIDC_GOOGLE_CLIENT_SECRET=...
+//	OIDC_GOOGLE_REDIRECT_URL=https://api.example.com/api/auth/oidc/google/callback
+//	OIDC_KEYCLOAK_ISSUER=https://sso.example.com/realms/main   (для google - не обов'язково)
+//	OIDC_KEYCLOAK_SCOPES="openid email profile"                (за замовчуванням)
+//
+// Обов'язкові поля перевіряє Validate.
+func loadOIDCProviders(raw string) []OIDCProvider {
+	var providers []OIDCProvider
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		})
+	}
+
+	return providers
+}
+
+// parseJWTKeys - ключі розділені ";", поля ключа - "," у вигляді name=value.
+// Алгоритми та секрети перевіряє Validate разом з ключами з файлу.
+func parseJWTKeys(raw string) ([]JWTKey, error) {
+	var keys []JWTKey
+
+	for _, entry := range strings.Split(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		k := JWTKey{Algorithm: "HS256"}
+		for _, field := range strings.Split(entry, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid field %q", field)
+			}
+			switch name {
+			case "kid":
+				k.ID = value
+			case "alg":
+				k.Algorithm = value
+			case "secret":
+				k.Secret = value
+			case "private":
+				k.PrivateKeyFile = value
+			case "public":
+				k.PublicKeyFile = value
+			default:
+				return nil, fmt.Errorf("unknown field %q", name)
+			}
+		}
+
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+
+	return keys, nil
+}
+
+// parseRateLimitRules - правила розділені ";", кожне - "<маршрут>=<кількість>/<період>/<ключ>".
+// Значення перевіряє Validate разом з правилами з файлу.
+func parseRateLimitRules(raw string) ([]RateLimitRule, error) {
+	var rules []RateLimitRule
+
+	for _, entry := range strings.Split(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, spec, ok := strings.Cut(entry, "=")
+		parts := strings.Split(spec, "/")
+		if !ok || len(parts) != 3 {
+			return nil, fmt.Errorf("invalid rule %q", entry)
+		}
+		requests, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid requests in rule %q", entry)
+		}
+		per, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid period in rule %q", entry)
+		}
+
+		rules = append(rules, RateLimitRule{
+			Route:    strings.TrimSpace(route),
+			Requests: requests,
+			Per:      per,
+			Key:      parts[2],
+		})
+	}
+
+	return rules, nil
+}
+
+// envReader - читає типізовані змінні оточення і накопичує помилки розбору
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) fail(key, value string) {
+	e.errs = append(e.errs, fmt.Errorf("%s is invalid: %q", key, value))
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func (e *envReader) boolean(key string, dst *bool) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail(key, v)
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) integer(key string, dst *int) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.fail(key, v)
+		return
+	}
+	*dst = n
+}
+
+// duration - у форматі time.ParseDuration ("30s", "2m")
+func (e *envReader) duration(key string, dst *time.Duration) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.fail(key, v)
+		return
+	}
+	*dst = d
+}
+
+// list - значення через sep, порожні елементи пропускаються
+func (e *envReader) list(key, sep string, dst *[]string) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(v, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"", ""},
+		{"postgres://app@db:5432/pc?sslmode=disable", "postgres://app@db:5432/pc?sslmode=disable"},
+		{"postgres://app:s3cret@db:5432/pc?sslmode=disable", "postgres://app:xxxxx@db:5432/pc?sslmode=disable"},
+		{"postgres://db/pc?user=app&password=s3cret", "postgres://db/pc?password=xxxxx&user=app"},
+		{"postgres://db/pc?sslmode=verify-full&sslkey=/k.pem&sslpassword=s3cret", "postgres://db/pc?sslkey=%2Fk.pem&sslmode=verify-full&sslpassword=xxxxx"},
+		{"postgres://app:one@db/pc?PASSWORD=two&password=three", "postgres://app:xxxxx@db/pc?PASSWORD=xxxxx&password=xxxxx"},
+		// Порожній пароль лишається порожнім
+		{"postgres://db/pc?password=", "postgres://db/pc?password="},
+		{"host=db user=app password=s3cret dbname=pc", "xxxxx"},
+		{"postgres://db/pc?password=%zz", "xxxxx"},
+	}
+	for _, tt := range tests {
+		if got := redactDSN(tt.dsn); got != tt.want {
+			t.Errorf("redactDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := Config{
+		Database: DatabaseConfig{DSN: "postgres://app@db/pc?password=db-secret"},
+		JWT:      JWTConfig{Keys: []JWTKey{{ID: "a", Algorithm: "HS256", Secret: "jwt-secret"}}},
+		Mail:     MailConfig{Password: "smtp-secret"},
+		OIDC:     []OIDCProvider{{Name: "google", ClientSecret: "oidc-secret"}},
+	}
+
+	out := cfg.Redacted()
+	printed := strings.Join([]string{out.Database.DSN, out.JWT.Keys[0].Secret, out.Mail.Password, out.OIDC[0].ClientSecret}, " ")
+	for _, secret := range []string{"db-secret", "jwt-secret", "smtp-secret", "oidc-secret"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("%q is not redacted: %s", secret, printed)
+		}
+	}
+
+	// Оригінал не змінено
+	if cfg.JWT.Keys[0].Secret != "jwt-secret" || cfg.OIDC[0].ClientSecret != "oidc-secret" {
+		t.Error("Redacted modified the original config")
+	}
+}
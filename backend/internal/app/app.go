@@ -0,0 +1,136 @@
+// Package app - життєвий цикл сервера: HTTP-сервер, пул БД і фонові задачі
+// запускаються разом і зупиняються у визначеному порядку з обмеженим часом.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/service"
+)
+
+// Worker - фонова задача, що працює, доки не скасовано ctx
+type Worker interface {
+	Run(ctx context.Context)
+}
+
+// App - володіє всіма ресурсами процесу; після Run їх закрито
+type App struct {
+	cfg     config.ServerConfig
+	server  *http.Server
+	db      *sql.DB
+	health  *service.HealthService
+	workers map[string]Worker
+}
+
+func New(cfg config.ServerConfig, handler http.Handler, db *sql.DB, health *service.HealthService) *App {
+	return &App{
+		cfg: cfg,
+		server: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		},
+		db:      db,
+		health:  health,
+		workers: map[string]Worker{},
+	}
+}
+
+// AddWorker - фонова задача, що стартує разом із сервером (до виклику Run)
+func (a *App) AddWorker(name string, w Worker) {
+	a.workers[name] = w
+}
+
+// Run - запускає фонові задачі та сервер і блокується, доки не скасовано ctx
+// (сигнал зупинки) або сервер не впав. Потім зупиняє все через shutdown.
+func (a *App) Run(ctx context.Context) error {
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for name, w := range a.workers {
+		wg.Add(1)
+		go func(name string, w Worker) {
+			defer wg.Done()
+			w.Run(logging.WithLogger(workersCtx, slog.Default().With("component", name)))
+		}(name, w)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server started", "port", a.cfg.Port)
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutdown signal received")
+	case runErr = <-serverErr:
+		slog.Error("server failed", "error", runErr)
+	}
+
+	return errors.Join(runErr, a.shutdown(stopWorkers, &wg))
+}
+
+// shutdown - порядок важливий:
+//  1. /readyz -> 503 і пауза DrainDelay, щоб балансувальник прибрав інстанс;
+//  2. сервер перестає приймати з'єднання і дообробляє поточні запити;
+//  3. фонові задачі отримують скасування і завершують поточну ітерацію;
+//  4. закривається пул БД, яким користувались і запити, і задачі.
+//
+// Кроки 2-3 разом обмежені ShutdownTimeout (пауза DrainDelay - окремо).
+func (a *App) shutdown(stopWorkers context.CancelFunc, wg *sync.WaitGroup) error {
+	a.health.SetShuttingDown()
+	slog.Info("draining", "delay", a.cfg.DrainDelay.String())
+	time.Sleep(a.cfg.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+		// Дедлайн вичерпано - обриваємо з'єднання, що лишились
+		_ = a.server.Close()
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("фонові задачі не завершились вчасно"))
+	}
+
+	if err := a.db.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		slog.Error("shutdown finished with errors", "error", err)
+	} else {
+		slog.Info("shutdown complete")
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+// Package apperror - доменні помилки, спільні для репозиторіїв, сервісів і HTTP-шару.
+// Кожна помилка належить до одного з видів (ErrNotFound, ErrConflict, ...), за яким
+// HTTP-шар обирає статус відповіді, і має стабільний код, на який може покладатися клієнт.
+// Помилки, що не належать до жодного виду, вважаються внутрішніми (збій БД тощо).
+package apperror
+
+import "errors"
+
+// Види помилок. Перевіряються через errors.Is(err, apperror.ErrNotFound);
+// текст виду - це загальний код помилки.
+var (
+	ErrNotFound     = errors.New("not_found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation_failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+var kinds = []error{ErrNotFound, ErrConflict, ErrValidation, ErrUnauthorized, ErrForbidden}
+
+// Error - доменна помилка з повідомленням для користувача.
+// Оголошується як змінна-сентинел і загортається через fmt.Errorf("%w ...").
+type Error struct {
+	Kind    error  // Один з видів вище
+	Code    string // Стабільний код, напр. "component_not_found"
+	Message string // Текст для користувача
+	Field   string // Поле запиту, до якого відноситься помилка (необов'язково)
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// KindOf - вид помилки або nil, якщо помилка внутрішня
+func KindOf(err error) error {
+	for _, k := range kinds {
+		if errors.Is(err, k) {
+			return k
+		}
+	}
+	return nil
+}
+
+// Code - стабільний код помилки: власний код *Error, загальний код виду або "internal_error"
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Code != "" {
+		return e.Code
+	}
+	if k := KindOf(err); k != nil {
+		return k.Error()
+	}
+	return "internal_error"
+}
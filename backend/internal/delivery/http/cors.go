@@ -0,0 +1,158 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pc-configurator/config"
+)
+
+// corsPolicy - зібрана з конфігурації політика CORS
+type corsPolicy struct {
+	anyOrigin   bool                // "*" у списку origin-ів
+	origins     map[string]struct{} // Точні origin-и
+	patterns    []originPattern     // https://*.example.com
+	credentials bool
+
+	methods       []string
+	allowMethods  string // Заголовки відповіді на preflight, зібрані заздалегідь
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// originPattern - шаблон піддоменів: origin = prefix + <мітки хоста> + suffix
+type originPattern struct {
+	prefix string // "https://"
+	suffix string // ".example.com"
+}
+
+func newCORSPolicy(cfg config.CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:       map[string]struct{}{},
+		credentials:   cfg.AllowCredentials,
+		methods:       cfg.AllowedMethods,
+		allowMethods:  strings.Join(cfg.AllowedMethods, ", "),
+		allowHeaders:  strings.Join(cfg.AllowedHeaders, ", "),
+		exposeHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*")
+			p.patterns = append(p.patterns, originPattern{prefix: scheme + "://", suffix: host})
+		default:
+			p.origins[origin] = struct{}{}
+		}
+	}
+
+	return p
+}
+
+// allowed - чи дозволено origin (порівнюємо без урахування регістру, як і браузер хости)
+func (p *corsPolicy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if _, ok := p.origins[origin]; ok {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (op originPattern) match(origin string) bool {
+	if !strings.HasPrefix(origin, op.prefix) || !strings.HasSuffix(origin, op.suffix) {
+		return false
+	}
+	// Між префіксом і суфіксом - лише мітки хоста, без порту, шляху чи user@
+	labels := origin[len(op.prefix) : len(origin)-len(op.suffix)]
+	if labels == "" {
+		return false
+	}
+	for _, c := range labels {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// CORSMiddleware - заголовки CORS за політикою з конфігурації.
+// Недозволений origin не отримує жодного заголовка Access-Control-*, а його preflight - 403.
+func (m *Middleware) CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := m.cors
+		origin := r.Header.Get("Origin")
+		// Preflight - OPTIONS з Access-Control-Request-Method; інший OPTIONS іде в роутер
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// Відповідь залежить від Origin - кеші (CDN, браузер) мають це враховувати
+		if !p.anyOrigin || p.credentials {
+			w.Header().Add("Vary", "Origin")
+		}
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !p.allowed(origin) {
+			if preflight {
+				respondWithError(w, http.StatusForbidden, "Origin не дозволено")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.anyOrigin && !p.credentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if method := r.Header.Get("Access-Control-Request-Method"); !slices.Contains(p.methods, method) {
+			respondWithError(w, http.StatusForbidden, "Метод не дозволено")
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", p.allowMethods)
+		if p.allowHeaders != "" {
+			w.Header().Set("Access-Control-Allow-Headers", p.allowHeaders)
+		}
+		if p.maxAge != "" {
+			w.Header().Set("Access-Control-Max-Age", p.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
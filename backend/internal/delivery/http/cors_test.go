@@ -0,0 +1,139 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pc-configurator/config"
+)
+
+func TestCORSPolicyAllowed(t *testing.T) {
+	p := newCORSPolicy(config.CORSConfig{
+		AllowedOrigins: []string{"https://shop.example.com", "https://*.example.org", "http://localhost:3000"},
+	})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://shop.example.com", true},
+		{"HTTPS://Shop.Example.com", true},
+		{"http://shop.example.com", false},
+		{"https://shop.example.com:8443", false},
+		{"https://evil.com", false},
+		{"https://shop.example.com.evil.com", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://A-1.example.org", true},
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"http://a.example.org", false},
+		{"https://a.example.org:444", false},
+		{"https://user@a.example.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"https://evilexample.org", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := p.allowed(tt.origin); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if wildcard := newCORSPolicy(config.CORSConfig{AllowedOrigins: []string{"*"}}); !wildcard.allowed("https://whatever.test") {
+		t.Error(`"*" does not allow an arbitrary origin`)
+	}
+	if none := newCORSPolicy(config.CORSConfig{}); none.allowed("https://shop.example.com") {
+		t.Error("empty policy allows an origin")
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	m := &Middleware{cors: newCORSPolicy(config.CORSConfig{
+		AllowedOrigins:   []string{"https://shop.example.com"},
+		AllowCredentials: true,
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		MaxAge:           10 * time.Minute,
+	})}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := m.CORSMiddleware(next)
+
+	serve := func(method, origin, requestMethod string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/components", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if requestMethod != "" {
+			r.Header.Set("Access-Control-Request-Method", requestMethod)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("allowed request", func(t *testing.T) {
+		w := serve("GET", "https://shop.example.com", "")
+		if w.Code != http.StatusTeapot {
+			t.Fatalf("status %d, request did not reach the handler", w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://shop.example.com" {
+			t.Errorf("Allow-Origin = %q", got)
+		}
+		if w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Error("Allow-Credentials is not set")
+		}
+		if w.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+			t.Errorf("Expose-Headers = %q", w.Header().Get("Access-Control-Expose-Headers"))
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Errorf("Vary = %q", w.Header().Values("Vary"))
+		}
+	})
+
+	t.Run("foreign origin", func(t *testing.T) {
+		w := serve("GET", "https://evil.com", "")
+		if w.Code != http.StatusTeapot {
+			t.Fatalf("status %d, simple request must reach the handler", w.Code)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Error("foreign origin got Allow-Origin")
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		w := serve("OPTIONS", "https://shop.example.com", "POST")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status %d, want 204", w.Code)
+		}
+		if w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" ||
+			w.Header().Get("Access-Control-Allow-Headers") != "Content-Type, Authorization" ||
+			w.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("preflight headers: %v", w.Header())
+		}
+	})
+
+	t.Run("preflight with a method that is not allowed", func(t *testing.T) {
+		if w := serve("OPTIONS", "https://shop.example.com", "DELETE"); w.Code != http.StatusForbidden {
+			t.Errorf("status %d, want 403", w.Code)
+		}
+	})
+
+	t.Run("preflight from a foreign origin", func(t *testing.T) {
+		w := serve("OPTIONS", "https://evil.com", "GET")
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status %d, want 403", w.Code)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Error("foreign origin got Allow-Origin")
+		}
+	})
+}
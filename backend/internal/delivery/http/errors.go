@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/service"
+	"pc-configurator/internal/validation"
+)
+
+// errorResponse - тіло відповіді з помилкою. Error - текст для людини,
+// Code - стабільний код, на який може покладатися клієнт, Errors - помилки по полях.
+type errorResponse struct {
+	Error  string            `json:"error"`
+	Code   string            `json:"code"`
+	Errors validation.Errors `json:"errors,omitempty"`
+}
+
+// kindStatus - HTTP-статус для кожного виду доменних помилок
+var kindStatus = map[error]int{
+	apperror.ErrNotFound:     http.StatusNotFound,
+	apperror.ErrConflict:     http.StatusConflict,
+	apperror.ErrValidation:   http.StatusBadRequest,
+	apperror.ErrUnauthorized: http.StatusUnauthorized,
+	apperror.ErrForbidden:    http.StatusForbidden,
+}
+
+// statusCodes - коди для помилок, які хендлери формують самі (некоректний JSON тощо)
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnprocessableEntity:   "unprocessable_entity",
+	http.StatusTooManyRequests:       "too_many_requests",
+	http.StatusBadGateway:            "bad_gateway",
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "internal_error"
+	}
+	respondWithJSON(w, status, errorResponse{Error: message, Code: code})
+}
+
+// respondWithDomainError - єдине місце, де помилки сервісів і репозиторіїв
+// перетворюються на HTTP-відповіді. Невідомі помилки (збій БД тощо) лише логуються:
+// клієнт отримує загальне 500 без внутрішніх подробиць.
+func respondWithDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(locked.RetryAfter)))
+		respondWithJSON(w, http.StatusTooManyRequests, errorResponse{Error: locked.Error(), Code: "login_locked"})
+		return
+	}
+
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		respondWithJSON(w, http.StatusBadRequest, errorResponse{
+			Error:  "Некоректні дані",
+			Code:   apperror.Code(err),
+			Errors: verrs,
+		})
+		return
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		resp := errorResponse{Error: appErr.Message, Code: appErr.Code}
+		if appErr.Field != "" {
+			resp.Errors = validation.Errors{appErr.Field: appErr.Message}
+		}
+		if status, ok := kindStatus[apperror.KindOf(appErr)]; ok {
+			respondWithJSON(w, status, resp)
+			return
+		}
+	}
+
+	logging.FromContext(r.Context()).Error("request failed", "error", err)
+	respondWithError(w, http.StatusInternalServerError, "Внутрішня помилка сервера")
+}
@@ -2,9 +2,19 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/metrics"
 	"pc-configurator/internal/models"
 	"pc-configurator/internal/repository"
 	"pc-configurator/internal/service"
@@ -16,6 +26,14 @@ type Handler struct {
 	authService  *service.AuthService
 	orderRepo    *repository.OrderRepo
 	recommendSvc *service.RecommendationService
+	catalogSvc   *service.CatalogService
+	importSvc    *service.CatalogImportService
+	priceSvc     *service.PriceHistoryService
+	watchSvc     *service.WatchService
+	oidcSvc      *service.OIDCService
+	accountSvc   *service.AccountService
+	detailSvc    *service.ComponentDetailService
+	healthSvc    *service.HealthService
 }
 
 // Оновили конструктор (додали repoOrder)
@@ -24,6 +42,12 @@ func NewHandler(
 	cs *service.CompatibilityService,
 	as *service.AuthService,
 	or *repository.OrderRepo,
+	is *service.CatalogImportService,
+	ps *service.PriceHistoryService,
+	ws *service.WatchService,
+	oidc *service.OIDCService,
+	acc *service.AccountService,
+	hs *service.HealthService,
 ) *Handler {
 	return &Handler{
 		compRepo:     r,
@@ -31,9 +55,36 @@ func NewHandler(
 		authService:  as,
 		orderRepo:    or,
 		recommendSvc: service.NewRecommendationService(r),
+		catalogSvc:   service.NewCatalogService(r),
+		importSvc:    is,
+		priceSvc:     ps,
+		watchSvc:     ws,
+		oidcSvc:      oidc,
+		accountSvc:   acc,
+		detailSvc:    service.NewComponentDetailService(r, ps),
+		healthSvc:    hs,
 	}
 }
 
+// --- HEALTH CHECKS ---
+
+// Healthz - GET /healthz - процес живий (без звернень до БД, щоб збій БД не призводив до перезапусків)
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz - GET /readyz - чи можна слати трафік: 200 або 503 з переліком перевірок
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.healthSvc.Readiness(r.Context())
+
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Ready {
+		respondWithJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, report)
+}
+
 // --- ORDER HANDLER (НОВЕ) ---
 func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req models.OrderRequest
@@ -55,9 +106,10 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	// Зберігаємо в базу
 	orderId, err := h.orderRepo.CreateOrder(req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
+	metrics.OrdersCreated.Inc()
 
 	// Відповідаємо "ОК"
 	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
@@ -89,13 +141,57 @@ func (h *Handler) GetAllComponents(w http.ResponseWriter, r *http.Request) {
 	// Викликаємо оновлений репозиторій
 	components, err := h.compRepo.GetAll(r.Context(), category, minPrice, maxPrice, search, sort)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, components)
 }
 
+// GetComponent - GET /api/components/{id}: сторінка компонента
+func (h *Handler) GetComponent(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Не знайдено")
+		return
+	}
+
+	detail, err := h.detailSvc.GetComponentDetail(r.Context(), id)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+// GetPriceHistory - GET /api/components/{id}/price-history?days=90
+func (h *Handler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Не знайдено")
+		return
+	}
+
+	days := 90
+	if d := r.URL.Query().Get("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed <= 0 || parsed > service.MaxPriceHistoryDays {
+			respondWithError(w, http.StatusBadRequest, "Некоректний параметр days")
+			return
+		}
+		days = parsed
+	}
+
+	history, err := h.priceSvc.GetPriceHistory(r.Context(), id, days)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
 type validateRequest struct {
 	ComponentIDs []int `json:"component_ids"`
 }
@@ -108,7 +204,7 @@ func (h *Handler) ValidateBuild(w http.ResponseWriter, r *http.Request) {
 	}
 	res, err := h.compService.ValidateBuild(r.Context(), req.ComponentIDs)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
 	respondWithJSON(w, http.StatusOK, res)
@@ -120,9 +216,9 @@ func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid input")
 		return
 	}
-	id, err := h.authService.CreateUser(input)
+	id, err := h.authService.CreateUser(r.Context(), input)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
 	respondWithJSON(w, http.StatusCreated, map[string]int{"id": id})
@@ -139,16 +235,361 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid input")
 		return
 	}
-	token, err := h.authService.GenerateToken(input.Email, input.Password)
+	tokens, err := h.authService.GenerateToken(r.Context(), input.Email, input.Password, clientIP(r))
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+type twoFactorSignInInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"` // Код з автентифікатора або код відновлення
+}
+
+// SignInTwoFactor - POST /api/auth/sign-in/2fa - другий крок входу, якщо sign-in
+// повернув two_factor_required
+func (h *Handler) SignInTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var input twoFactorSignInInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.ChallengeToken == "" || input.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібні challenge_token і code")
+		return
+	}
+
+	tokens, err := h.authService.VerifyTwoFactor(r.Context(), input.ChallengeToken, input.Code, clientIP(r))
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+type refreshTokenInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken - POST /api/auth/refresh - нова пара токенів в обмін на refresh-токен
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var input refreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.RefreshToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен refresh_token")
+		return
+	}
+
+	tokens, err := h.authService.RefreshTokens(input.RefreshToken)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Login failed")
+		respondWithDomainError(w, r, err)
 		return
 	}
-	respondWithJSON(w, http.StatusOK, map[string]string{"token": token})
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+// Logout - POST /api/auth/logout - відкликає refresh-токен поточної сесії
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var input refreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.RefreshToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен refresh_token")
+		return
+	}
+
+	if err := h.authService.Logout(input.RefreshToken); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Ви вийшли з акаунту"})
+}
+
+// --- EMAIL VERIFICATION / PASSWORD RESET ---
+
+// RequestEmailVerification - POST /api/auth/verify-email/request - повторно надіслати лист
+func (h *Handler) RequestEmailVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(r.Context(), userID); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Лист надіслано"})
+}
+
+type accountTokenInput struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ConfirmEmail - POST /api/auth/verify-email/confirm - {"token": "..."}
+func (h *Handler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	var input accountTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен token")
+		return
+	}
+
+	if err := h.authService.VerifyEmail(input.Token); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Email підтверджено"})
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// RequestPasswordReset - POST /api/auth/password-reset/request - {"email": "..."}
+// Відповідь однакова незалежно від того, чи існує такий користувач
+func (h *Handler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var input passwordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен email")
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), input.Email); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Якщо такий акаунт існує, ми надіслали лист з інструкціями",
+	})
+}
+
+// ConfirmPasswordReset - POST /api/auth/password-reset/confirm - {"token": "...", "new_password": "..."}
+func (h *Handler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var input accountTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен token")
+		return
+	}
+
+	if err := h.authService.ResetPassword(input.Token, input.NewPassword); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Пароль змінено, увійдіть з новим паролем"})
+}
+
+// --- OIDC (ВХІД ЧЕРЕЗ GOOGLE ТА ІНШИХ ПРОВАЙДЕРІВ) ---
+
+// Cookie зі станом входу між /start і /callback
+const oidcStateCookie = "oidc_state"
+
+// OIDCProviders - GET /api/auth/oidc/providers - для яких провайдерів показувати кнопки входу
+func (h *Handler) OIDCProviders(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string][]string{"providers": h.oidcSvc.Providers()})
+}
+
+// StartOIDCLogin - GET /api/auth/oidc/{provider}/start - перенаправляє на сторінку входу провайдера
+func (h *Handler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+	authURL, state, err := h.oidcSvc.StartLogin(r.Context(), provider)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownOIDCProvider) {
+			respondWithDomainError(w, r, err)
+			return
+		}
+		// Провайдер недоступний (discovery не вдався) - подробиці лише в лозі
+		logging.FromContext(r.Context()).Error("OIDC login failed", "provider", provider, "error", err)
+		respondWithError(w, http.StatusBadGateway, "Провайдер входу тимчасово недоступний")
+		return
+	}
+
+	// SameSite=Lax: cookie надсилається при поверненні з сайту провайдера (GET-навігація)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc/",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback - GET /api/auth/oidc/{provider}/callback - сюди провайдер повертає користувача.
+// Результат передаємо фронтенду перенаправленням на {APP_URL}/auth/callback#token=...
+// (або #error=...), бо це навігація браузера, а не запит з JS.
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+
+	// Cookie одноразовий - видаляємо за будь-якого результату
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/auth/oidc/", MaxAge: -1, HttpOnly: true})
+
+	q := r.URL.Query()
+	params := url.Values{}
+
+	if providerErr := q.Get("error"); providerErr != "" {
+		// Користувач скасував вхід або провайдер відмовив
+		params.Set("error", providerErr)
+		http.Redirect(w, r, h.oidcSvc.FrontendCallbackURL(params), http.StatusFound)
+		return
+	}
+
+	var signedState string
+	if c, err := r.Cookie(oidcStateCookie); err == nil {
+		signedState = c.Value
+	}
+
+	tokens, err := h.oidcSvc.FinishLogin(r.Context(), provider, signedState, q.Get("state"), q.Get("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownOIDCProvider):
+			respondWithDomainError(w, r, err)
+			return
+		case errors.Is(err, service.ErrInvalidOIDCState), errors.Is(err, service.ErrOIDCEmailNotVerified),
+			errors.Is(err, service.ErrOIDCAccountNotVerified), errors.Is(err, repository.ErrIdentityTaken):
+			params.Set("error", err.Error())
+		default:
+			logging.FromContext(r.Context()).Warn("OIDC login failed", "provider", provider, "error", err)
+			params.Set("error", "Не вдалося увійти через "+provider)
+		}
+		http.Redirect(w, r, h.oidcSvc.FrontendCallbackURL(params), http.StatusFound)
+		return
+	}
+
+	if tokens.TwoFactorRequired {
+		// Фронтенд запитає код і завершить вхід через /api/auth/sign-in/2fa
+		params.Set("two_factor_required", "true")
+		params.Set("challenge_token", tokens.ChallengeToken)
+	} else {
+		params.Set("token", tokens.AccessToken)
+		params.Set("refresh_token", tokens.RefreshToken)
+	}
+	params.Set("expires_in", strconv.Itoa(tokens.ExpiresIn))
+	http.Redirect(w, r, h.oidcSvc.FrontendCallbackURL(params), http.StatusFound)
 }
 
 // --- PROFILE HANDLERS (НОВІ) ---
 
+// --- ДВОФАКТОРНА АВТЕНТИФІКАЦІЯ ---
+
+type twoFactorCodeInput struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+// TwoFactorStatus - GET /api/auth/2fa - чи увімкнено 2FA і скільки лишилось кодів відновлення
+func (h *Handler) TwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	tf, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled":             tf.Enabled(),
+		"recovery_codes_left": tf.RecoveryCodesLeft,
+	})
+}
+
+// EnrollTwoFactor - POST /api/auth/2fa/enroll - новий секрет і otpauth:// URI для QR-коду
+func (h *Handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(userID)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor - POST /api/auth/2fa/confirm - {"code": "123456"} вмикає 2FA
+// і повертає коди відновлення (показуються один раз)
+func (h *Handler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен code")
+		return
+	}
+
+	codes, err := h.authService.ConfirmTOTP(userID, input.Code)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// DisableTwoFactor - POST /api/auth/2fa/disable - {"password": "...", "code": "..."}
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" || input.Password == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібні password і code")
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, input.Password, input.Code); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Двофакторну автентифікацію вимкнено"})
+}
+
+// RegenerateRecoveryCodes - POST /api/auth/2fa/recovery-codes - {"code": "..."} - нові коди замість старих
+func (h *Handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Потрібен code")
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, input.Code)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
 // GetProfile - GET /api/auth/me - Отримання інформації про поточного користувача
 func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// Отримуємо ID користувача з контексту (встановлено AuthMiddleware)
@@ -161,7 +602,7 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// Отримуємо дані користувача з БД
 	user, err := h.authService.GetUserByID(userID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Користувача не знайдено")
+		respondWithDomainError(w, r, err)
 		return
 	}
 
@@ -170,9 +611,62 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		"id":    user.ID,
 		"name":  user.Name,
 		"email": user.Email,
+		"role":  user.Role,
+
+		"email_verified": user.EmailVerifiedAt != nil,
 	})
 }
 
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// DeleteAccount - DELETE /api/auth/me - {"password": "..."}; без пароля - лише одразу після входу
+// (паролем або через OIDC). Замовлення знеособлюються і лишаються для обліку, решта даних видаляється.
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	// Тіло не обов'язкове: без пароля підтвердженням слугує свіжий вхід
+	var req deleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+
+	authTime, _ := r.Context().Value(CtxAuthTime).(time.Time)
+	if err := h.accountSvc.DeleteAccount(r.Context(), userID, req.Password, authTime); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Акаунт видалено"})
+}
+
+// ExportAccount - GET /api/auth/me/export - усі персональні дані файлом JSON
+func (h *Handler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	export, err := h.accountSvc.ExportData(r.Context(), userID)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("pc-configurator-export-%d-%s.json", userID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, export)
+}
+
 // ChangePassword - POST /api/auth/change-password - Зміна пароля
 type changePasswordRequest struct {
 	CurrentPassword string `json:"current_password"`
@@ -193,15 +687,18 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Змінюємо пароль через сервіс
-	err := h.authService.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
+	// Змінюємо пароль через сервіс (інші сесії при цьому завершуються)
+	tokens, err := h.authService.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Пароль успішно змінено",
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       "Пароль успішно змінено",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -230,7 +727,7 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.authService.UpdateProfile(userID, req.Name, req.Email); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithDomainError(w, r, err)
 		return
 	}
 
@@ -247,9 +744,9 @@ func (h *Handler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Отримуємо замовлення користувача
-	orders, err := h.orderRepo.GetUserOrders(userID)
+	orders, err := h.orderRepo.GetUserOrders(r.Context(), userID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Помилка при отриманні замовлень")
+		respondWithDomainError(w, r, err)
 		return
 	}
 
@@ -261,6 +758,300 @@ func (h *Handler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, orders)
 }
 
+// --- WATCHLIST HANDLERS ---
+
+// GetWatches - GET /api/watches - відстеження цін поточного користувача
+func (h *Handler) GetWatches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	watches, err := h.watchSvc.GetUserWatches(r.Context(), userID)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, watches)
+}
+
+// CreateWatch - POST /api/watches - нове відстеження ціни компонента або збірки
+func (h *Handler) CreateWatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	var watch models.Watch
+	if err := json.NewDecoder(r.Body).Decode(&watch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+	watch.UserID = userID
+
+	id, err := h.watchSvc.CreateWatch(r.Context(), watch)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+// DeleteWatch - DELETE /api/watches/{id}
+func (h *Handler) DeleteWatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Некоректний id відстеження")
+		return
+	}
+	if err := h.watchSvc.DeleteWatch(r.Context(), userID, id); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Відстеження видалено"})
+}
+
+// GetNotifications - GET /api/notifications - останні сповіщення користувача
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	notifications, err := h.watchSvc.GetNotifications(r.Context(), userID)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, notifications)
+}
+
+// MarkNotificationsRead - POST /api/notifications/read - {"id": 5} або {} для всіх
+type markReadRequest struct {
+	ID int64 `json:"id"`
+}
+
+func (h *Handler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(CtxUserID).(int)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Не авторизовано")
+		return
+	}
+
+	var req markReadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+			return
+		}
+	}
+
+	if err := h.watchSvc.MarkNotificationsRead(r.Context(), userID, req.ID); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Сповіщення прочитано"})
+}
+
+// --- ADMIN HANDLERS ---
+
+// SetUserRole - PUT /api/admin/users/role - Зміна ролі користувача (лише admin)
+type setUserRoleRequest struct {
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func (h *Handler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	var req setUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+
+	if req.UserID == 0 || !models.IsValidRole(req.Role) {
+		respondWithError(w, http.StatusBadRequest, "Потрібні user_id та коректна роль")
+		return
+	}
+
+	if err := h.authService.SetUserRole(req.UserID, req.Role); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Роль оновлено"})
+}
+
+// GetAllOrders - GET /api/admin/orders?status= - замовлення всіх покупців (manager, admin)
+func (h *Handler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.IsValidOrderStatus(status) {
+		respondWithError(w, http.StatusBadRequest, "Невідомий статус замовлення")
+		return
+	}
+
+	orders, err := h.orderRepo.GetAllOrders(r.Context(), status)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, orders)
+}
+
+// UpdateOrderStatus - PUT /api/admin/orders/{id}/status - зміна статусу замовлення (manager, admin)
+type updateOrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (h *Handler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Некоректний ID замовлення")
+		return
+	}
+
+	var req updateOrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+	if !models.IsValidOrderStatus(req.Status) {
+		respondWithError(w, http.StatusBadRequest, "Невідомий статус замовлення")
+		return
+	}
+
+	if err := h.orderRepo.UpdateOrderStatus(r.Context(), id, req.Status); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Статус замовлення оновлено"})
+}
+
+// CreateComponent - POST /api/admin/components - новий компонент каталогу (лише admin)
+func (h *Handler) CreateComponent(w http.ResponseWriter, r *http.Request) {
+	var c models.Component
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+
+	id, err := h.catalogSvc.CreateComponent(r.Context(), c)
+	if err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+// UpdateComponent - PUT /api/admin/components/{id} - повне оновлення компонента (лише admin)
+func (h *Handler) UpdateComponent(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Некоректний id компонента")
+		return
+	}
+
+	var c models.Component
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Некоректні дані")
+		return
+	}
+	c.ID = id
+
+	if err := h.catalogSvc.UpdateComponent(r.Context(), c); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Компонент оновлено"})
+}
+
+// DeleteComponent - DELETE /api/admin/components/{id} - м'яке видалення (лише admin)
+func (h *Handler) DeleteComponent(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Некоректний id компонента")
+		return
+	}
+
+	if err := h.catalogSvc.DeleteComponent(r.Context(), id); err != nil {
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Компонент видалено"})
+}
+
+// maxImportSize - максимальний розмір прайс-листа, що приймає API (10 МБ)
+const maxImportSize = 10 << 20
+
+// ImportComponents - POST /api/admin/components/import - Імпорт прайс-листа (лише admin)
+// Тіло запиту - сам файл. Параметри: format=csv|json (або з Content-Type),
+// dry_run=true, delimiter=; та map=sku=Артикул,price=Ціна
+func (h *Handler) ImportComponents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		if strings.Contains(r.Header.Get("Content-Type"), "json") {
+			format = service.ImportFormatJSON
+		} else {
+			format = service.ImportFormatCSV
+		}
+	}
+
+	mapping, err := service.ParseColumnMapping(q.Get("map"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := service.ImportOptions{
+		Format:  format,
+		Mapping: mapping,
+		DryRun:  q.Get("dry_run") == "true" || q.Get("dry_run") == "1",
+	}
+	if d := q.Get("delimiter"); d != "" {
+		opts.Delimiter, _ = utf8.DecodeRuneInString(d)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := h.importSvc.Import(r.Context(), body, opts)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Файл завеликий (максимум %d МБ)", maxImportSize>>20))
+			return
+		}
+		if errors.Is(err, service.ErrImportHasErrors) {
+			respondWithJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":  err.Error(),
+				"code":   apperror.Code(err),
+				"report": report,
+			})
+			return
+		}
+		respondWithDomainError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
 // --- RECOMMENDATION HANDLER (НОВОЕ) ---
 func (h *Handler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
 	var req service.RecommendationRequest
@@ -272,7 +1063,7 @@ func (h *Handler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.recommendSvc.GetRecommendation(r.Context(), req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Помилка при підборі конфігурації")
+		respondWithDomainError(w, r, err)
 		return
 	}
 
@@ -280,13 +1071,24 @@ func (h *Handler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
 }
 
 // Helpers
+
+// pathID - додатний числовий параметр шляху, напр. {id} у /api/watches/{id}
+// Старі маршрути без {id} у шаблоні передають його в query (?id=) - тоді читаємо звідти.
+func pathID(r *http.Request, name string) (int, bool) {
+	raw := r.PathValue(name)
+	if raw == "" {
+		raw = r.URL.Query().Get(name)
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	w.Write(response)
 }
-
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
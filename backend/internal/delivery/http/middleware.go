@@ -2,9 +2,21 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"pc-configurator/config"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/metrics"
+	"pc-configurator/internal/repository"
 	"pc-configurator/internal/service"
 )
 
@@ -12,38 +24,193 @@ import (
 // Використовуємо власний тип, щоб уникнути колізій з іншими пакетами
 type contextKey string
 
-const CtxUserID contextKey = "userID"
+const (
+	CtxUserID   contextKey = "userID"
+	CtxUserRole contextKey = "userRole"
+	CtxAuthTime contextKey = "authTime" // time.Time: коли користувач увійшов (для повторного підтвердження)
+	CtxClientIP contextKey = "clientIP"
+
+	ctxRequestInfo contextKey = "requestInfo"
+)
+
+// requestIDHeader - ID запиту: приймаємо від клієнта або проксі, інакше генеруємо
+const requestIDHeader = "X-Request-ID"
 
 // Middleware - структура, яка тримає залежності
 type Middleware struct {
 	authService *service.AuthService
+	trustProxy  bool        // Чи довіряти X-Forwarded-For (сервер стоїть за проксі, напр. Render)
+	cors        *corsPolicy // Які origin-и можуть звертатися до API з браузера
+
+	limiter        repository.RateLimiter
+	rateLimits     map[string]config.RateLimitRule // Правила за шаблоном маршруту
+	rateLimitsUsed map[string]bool                 // Які з них знайшлися серед маршрутів
 }
 
 // NewMiddleware - конструктор
-func NewMiddleware(as *service.AuthService) *Middleware {
-	return &Middleware{authService: as}
+func NewMiddleware(as *service.AuthService, trustProxy bool, cors config.CORSConfig, limiter repository.RateLimiter, rateLimit config.RateLimitConfig) *Middleware {
+	m := &Middleware{
+		authService:    as,
+		trustProxy:     trustProxy,
+		cors:           newCORSPolicy(cors),
+		limiter:        limiter,
+		rateLimits:     map[string]config.RateLimitRule{},
+		rateLimitsUsed: map[string]bool{},
+	}
+	if rateLimit.Enabled {
+		for _, rule := range rateLimit.Routes {
+			m.rateLimits[rule.Route] = rule
+		}
+	}
+	return m
 }
 
-// CORSMiddleware - налаштовує заголовки для взаємодії з React
-func (m *Middleware) CORSMiddleware(next http.Handler) http.Handler {
+// ClientIPMiddleware - визначає IP клієнта і кладе його в контекст.
+// За проксі беремо останню адресу з X-Forwarded-For: її дописав наш проксі,
+// а все лівіше клієнт міг підробити.
+func (m *Middleware) ClientIPMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Дозволяємо запити з будь-якого джерела (для розробки)
-		// У продакшні тут має бути конкретний домен фронтенду
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Браузер спочатку відправляє OPTIONS запит (preflight), щоб перевірити дозволи.
-		// Ми повинні відповісти 200 OK і зупинити обробку.
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		if m.trustProxy {
+			if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+				parts := strings.Split(xff, ",")
+				if last := strings.TrimSpace(parts[len(parts)-1]); last != "" {
+					ip = last
+				}
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), CtxClientIP, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientIP - IP з контексту (ClientIPMiddleware) або RemoteAddr як запасний варіант
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(CtxClientIP).(string); ok {
+		return ip
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// requestInfo - те, що дізнаються внутрішні шари (роутер, AuthMiddleware)
+// і що потрібно зовнішнім middleware для логу та метрик
+type requestInfo struct {
+	userID int
+	route  string // Шаблон маршруту без методу, напр. /api/components/{id}
+}
+
+// withRequestInfo - requestInfo запиту; створює його, якщо middleware вище ще не створив
+func withRequestInfo(ctx context.Context) (*requestInfo, context.Context) {
+	if info, ok := ctx.Value(ctxRequestInfo).(*requestInfo); ok {
+		return info, ctx
+	}
+	info := &requestInfo{}
+	return info, context.WithValue(ctx, ctxRequestInfo, info)
+}
+
+// statusRecorder - запам'ятовує статус відповіді для логу
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RequestLogMiddleware - присвоює запиту ID, кладе в контекст логер з цим ID
+// і після відповіді пише один рядок: метод, шлях, статус, тривалість, користувач.
+// Має стояти всередині ClientIPMiddleware, щоб бачити IP клієнта.
+func (m *Middleware) RequestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		logger := slog.Default().With("request_id", id)
+		info, ctx := withRequestInfo(logging.WithLogger(r.Context(), logger))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", info.route,
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", clientIP(r),
+		}
+		if info.userID != 0 {
+			attrs = append(attrs, "user_id", info.userID)
 		}
+		logger.Log(r.Context(), level, "request", attrs...)
+	})
+}
 
-		next.ServeHTTP(w, r)
+// MetricsMiddleware - лічильник і тривалість запитів за маршрутом і статусом.
+// Мітка route - шаблон ServeMux, а не шлях, щоб /api/components/1, /2 ... не
+// створювали окремих часових рядів.
+func (m *Middleware) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info, ctx := withRequestInfo(r.Context())
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		route := info.route
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
 	})
 }
 
+// validRequestID - чужий ID приймаємо, лише якщо він короткий і без спецсимволів (йде в логи)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.ContainsRune("-_.:", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // AuthMiddleware - перевіряє наявність та валідність JWT токена
 func (m *Middleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -62,17 +229,59 @@ func (m *Middleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		// 3. Парсимо токен через сервіс
-		userId, err := m.authService.ParseToken(headerParts[1])
+		claims, err := m.authService.ParseAccessToken(headerParts[1])
 		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "Невалідний токен: "+err.Error())
+			// Причину (прострочений, чужий підпис ...) клієнту не показуємо - лише в лог
+			logging.FromContext(r.Context()).Info("invalid access token", "error", err)
+			respondWithJSON(w, http.StatusUnauthorized, errorResponse{Error: "Невалідний або прострочений токен", Code: "invalid_token"})
 			return
 		}
 
 		// 4. Записуємо ID користувача у контекст
 		// Тепер у хендлерах ми зможемо дізнатися, хто саме робить запит
+		userId := claims.UserID
 		ctx := context.WithValue(r.Context(), CtxUserID, userId)
+		ctx = context.WithValue(ctx, CtxUserRole, claims.Role)
+		ctx = context.WithValue(ctx, CtxAuthTime, claims.AuthTime)
+
+		// Подальші записи в лог і підсумковий рядок запиту - з ID користувача
+		ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("user_id", userId))
+		if info, ok := ctx.Value(ctxRequestInfo).(*requestInfo); ok {
+			info.userID = userId
+		}
 
 		// Передаємо керування наступному хендлеру, але вже з оновленим контекстом
 		next(w, r.WithContext(ctx))
 	}
 }
+
+// RequireRole - пропускає запит лише для користувачів з однією з вказаних ролей.
+// Має використовуватися всередині AuthMiddleware. Роль перечитується з БД, а не береться
+// з токена: знижений у правах адміністратор втрачає доступ одразу, а не коли мине access TTL.
+func (m *Middleware) RequireRole(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(CtxUserID).(int)
+		if !ok {
+			respondWithError(w, http.StatusUnauthorized, "Потрібна авторизація")
+			return
+		}
+
+		user, err := m.authService.GetUserByID(userID)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondWithError(w, http.StatusUnauthorized, "Потрібна авторизація")
+			return
+		}
+		if err != nil {
+			respondWithDomainError(w, r, err)
+			return
+		}
+
+		if slices.Contains(roles, user.Role) {
+			ctx := context.WithValue(r.Context(), CtxUserRole, user.Role)
+			next(w, r.WithContext(ctx))
+			return
+		}
+
+		respondWithError(w, http.StatusForbidden, "Недостатньо прав доступу")
+	}
+}
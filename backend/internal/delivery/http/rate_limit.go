@@ -0,0 +1,74 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+)
+
+// RateLimitMiddleware - обмежує частоту запитів до маршруту pattern, якщо для нього
+// є правило в конфігурації; інакше повертає next без змін.
+// Стоїть перед AuthMiddleware, тому користувача визначає за токеном сам.
+func (m *Middleware) RateLimitMiddleware(pattern string, next http.Handler) http.Handler {
+	rule, ok := m.rateLimits[pattern]
+	if !ok || m.limiter == nil {
+		return next
+	}
+	m.rateLimitsUsed[pattern] = true
+
+	limit := models.RateLimit{Requests: rule.Requests, Per: rule.Per}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := m.limiter.Take(r.Context(), pattern+"|"+m.rateLimitKey(r, rule), limit)
+		if err != nil {
+			// Збій лічильника не повинен класти API - пропускаємо запит
+			logging.FromContext(r.Context()).Error("rate limiter failed", "route", pattern, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rule.Requests))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			respondWithError(w, http.StatusTooManyRequests, "Забагато запитів, спробуйте пізніше")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey - ID користувача з валідного токена для правил key=user, інакше IP клієнта
+func (m *Middleware) rateLimitKey(r *http.Request, rule config.RateLimitRule) string {
+	if rule.Key == "user" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if userID, _, err := m.authService.ParseToken(token); err == nil {
+				return "user:" + strconv.Itoa(userID)
+			}
+		}
+	}
+	return "ip:" + clientIP(r)
+}
+
+// UnusedRateLimits - правила для маршрутів, яких немає в роутері (найчастіше - одруківка)
+func (m *Middleware) UnusedRateLimits() []string {
+	var unused []string
+	for pattern := range m.rateLimits {
+		if !m.rateLimitsUsed[pattern] {
+			unused = append(unused, pattern)
+		}
+	}
+	return unused
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
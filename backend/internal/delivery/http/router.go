@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/metrics"
+	"pc-configurator/internal/models"
+)
+
+// NewRouter - усі маршрути API. Шаблони ServeMux (Go 1.22+) містять метод і параметри
+// шляху, тож запит з іншим методом отримує 405 із заголовком Allow.
+// Вимкнені у features частини API не реєструються зовсім (404).
+func NewRouter(h *Handler, mw *Middleware, features config.FeatureFlags) http.Handler {
+	mux := http.NewServeMux()
+
+	auth := mw.AuthMiddleware
+	admin := func(next http.HandlerFunc) http.HandlerFunc {
+		return mw.AuthMiddleware(mw.RequireRole(next, models.RoleAdmin))
+	}
+	staff := func(next http.HandlerFunc) http.HandlerFunc {
+		return mw.AuthMiddleware(mw.RequireRole(next, models.RoleManager, models.RoleAdmin))
+	}
+	// deprecated - старі адреси з ?id= замість /{id}: працюють як раніше, але клієнт бачить,
+	// що їх пора замінити (заголовок Deprecation, draft-ietf-httpapi-deprecation-header)
+	deprecated := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			next(w, r)
+		}
+	}
+	// handle - реєструє маршрут з обмеженням частоти, якщо воно задане для нього в конфігурації
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, mw.RateLimitMiddleware(pattern, h))
+	}
+
+	// Вхід, реєстрація, відновлення доступу
+	handle("POST /api/auth/sign-up", h.SignUp)
+	handle("POST /api/auth/sign-in", h.SignIn)
+	handle("POST /api/auth/sign-in/2fa", h.SignInTwoFactor)
+	handle("POST /api/auth/refresh", h.RefreshToken)
+	handle("POST /api/auth/logout", h.Logout)
+	handle("POST /api/auth/verify-email/confirm", h.ConfirmEmail)
+	handle("POST /api/auth/password-reset/request", h.RequestPasswordReset)
+	handle("POST /api/auth/password-reset/confirm", h.ConfirmPasswordReset)
+	handle("GET /api/auth/oidc/providers", h.OIDCProviders)
+	handle("GET /api/auth/oidc/{provider}/start", h.StartOIDCLogin)
+	handle("GET /api/auth/oidc/{provider}/callback", h.OIDCCallback)
+
+	// Каталог і конфігуратор
+	handle("GET /api/components", h.GetAllComponents)
+	handle("GET /api/components/{id}", h.GetComponent)
+	handle("GET /api/components/{id}/price-history", h.GetPriceHistory)
+	handle("POST /api/validate", h.ValidateBuild)
+	handle("POST /api/recommend", h.GetRecommendation)
+
+	// Профіль (потребує авторизації)
+	handle("GET /api/auth/me", auth(h.GetProfile))
+	handle("DELETE /api/auth/me", auth(h.DeleteAccount))
+	handle("GET /api/auth/me/export", auth(h.ExportAccount))
+	handle("POST /api/auth/change-password", auth(h.ChangePassword))
+	handle("PUT /api/auth/update-profile", auth(h.UpdateProfile))
+	handle("POST /api/auth/verify-email/request", auth(h.RequestEmailVerification))
+	handle("GET /api/auth/2fa", auth(h.TwoFactorStatus))
+	handle("POST /api/auth/2fa/enroll", auth(h.EnrollTwoFactor))
+	handle("POST /api/auth/2fa/confirm", auth(h.ConfirmTwoFactor))
+	handle("POST /api/auth/2fa/disable", auth(h.DisableTwoFactor))
+	handle("POST /api/auth/2fa/recovery-codes", auth(h.RegenerateRecoveryCodes))
+
+	// Замовлення, відстеження цін, сповіщення
+	handle("POST /api/orders", auth(h.CreateOrder))
+	handle("GET /api/orders/my", auth(h.GetUserOrders))
+	handle("GET /api/watches", auth(h.GetWatches))
+	handle("POST /api/watches", auth(h.CreateWatch))
+	handle("DELETE /api/watches/{id}", auth(h.DeleteWatch))
+	handle("DELETE /api/watches", deprecated(auth(h.DeleteWatch))) // ?id=
+	handle("GET /api/notifications", auth(h.GetNotifications))
+	handle("POST /api/notifications/read", auth(h.MarkNotificationsRead))
+
+	// Адміністрування
+	handle("POST /api/admin/components", admin(h.CreateComponent))
+	handle("PUT /api/admin/components/{id}", admin(h.UpdateComponent))
+	handle("DELETE /api/admin/components/{id}", admin(h.DeleteComponent))
+	handle("PUT /api/admin/components", deprecated(admin(h.UpdateComponent)))    // ?id=
+	handle("DELETE /api/admin/components", deprecated(admin(h.DeleteComponent))) // ?id=
+	handle("POST /api/admin/components/import", admin(h.ImportComponents))
+	handle("PUT /api/admin/users/role", admin(h.SetUserRole))
+	handle("GET /api/admin/orders", staff(h.GetAllOrders))
+	handle("PUT /api/admin/orders/{id}/status", staff(h.UpdateOrderStatus))
+
+	// Метрики Prometheus і перевірки стану
+	if features.Metrics {
+		mux.Handle("GET /metrics", metrics.Handler())
+	}
+	handle("GET /healthz", h.Healthz)
+	handle("GET /readyz", h.Readyz)
+
+	return &router{mux: mux}
+}
+
+// router - ServeMux, що відповідає на невідомий шлях чи метод у форматі API
+type router struct {
+	mux *http.ServeMux
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, pattern := rt.mux.Handler(r)
+	if pattern == "" {
+		// ServeMux пише 404/405 звичайним текстом - замінюємо на {"error": ...}
+		w = &muxErrorWriter{ResponseWriter: w}
+	}
+
+	// Маршрут для логу та метрик (requestInfo створюють middleware вище)
+	if info, ok := r.Context().Value(ctxRequestInfo).(*requestInfo); ok {
+		info.route = pattern
+		if _, path, found := strings.Cut(pattern, " "); found {
+			info.route = path
+		}
+	}
+
+	rt.mux.ServeHTTP(w, r)
+}
+
+// muxErrorWriter - підміняє текстові 404/405 від ServeMux на JSON (Allow лишається)
+type muxErrorWriter struct {
+	http.ResponseWriter
+	replaced bool
+}
+
+func (w *muxErrorWriter) WriteHeader(code int) {
+	var message string
+	switch code {
+	case http.StatusNotFound:
+		message = "Не знайдено"
+	case http.StatusMethodNotAllowed:
+		message = "Метод не підтримується"
+	default:
+		// Напр. перенаправлення на шлях без "//" - пропускаємо як є
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.replaced = true
+	respondWithError(w.ResponseWriter, code, message)
+}
+
+func (w *muxErrorWriter) Write(b []byte) (int, error) {
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,30 @@
+// Package logging - структуровані логи (log/slog, JSON).
+// Логер з ID запиту передається через context, тож сервіси та репозиторії,
+// що отримують ctx, пишуть у лог з тим самим request_id, що й HTTP-шар.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+type ctxKey struct{}
+
+// New - JSON-логер; рівень можна змінювати на льоту через level
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// WithLogger - контекст, з якого FromContext поверне logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext - логер запиту або глобальний (фонові задачі, CLI)
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,64 @@
+// Package metrics - метрики Prometheus, що віддаються на /metrics.
+// Колектори реєструються в стандартному реєстрі разом з метриками Go-рантайму та процесу.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pc_configurator"
+
+var (
+	// HTTPRequests - кількість запитів за маршрутом (шаблон ServeMux) і статусом
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration - тривалість обробки запитів
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// OrdersCreated - оформлені замовлення
+	OrdersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders created.",
+	})
+
+	// ValidationFailures - збірки, що не пройшли перевірку, за правилом (socket, memory_type ...)
+	ValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "build_validation_failures_total",
+		Help:      "Build compatibility check failures by rule.",
+	}, []string{"rule"})
+
+	// RecommendationDuration - час підбору конфігурації
+	RecommendationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "recommendation_duration_seconds",
+		Help:      "Time spent building a recommended configuration.",
+		Buckets:   prometheus.DefBuckets,
+	})
+)
+
+// RegisterDBStats - статистика пулу з'єднань (sql.DB.Stats()) у кожному зборі метрик
+func RegisterDBStats(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler - ендпоінт /metrics у форматі Prometheus
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AccountExport - усі персональні дані користувача (GET /api/auth/me/export)
+type AccountExport struct {
+	ExportedAt    time.Time      `json:"exported_at"`
+	Profile       AccountProfile `json:"profile"`
+	Identities    []UserIdentity `json:"identities"`
+	Orders        []Order        `json:"orders"`
+	Watches       []Watch        `json:"watches"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// AccountProfile - профіль без хеша пароля
+type AccountProfile struct {
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+// ImportItem - один рядок прайс-листа постачальника, вже перетворений у компонент
+type ImportItem struct {
+	Row         int    `json:"row"` // Номер рядка у файлі (для звіту про помилки)
+	SupplierSKU string `json:"supplier_sku"`
+	Component   Component
+}
+
+// Дії над компонентом під час імпорту
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// FieldChange - зміна одного поля (старе -> нове значення)
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ImportChange - що станеться (або сталося) з конкретним SKU
+type ImportChange struct {
+	SupplierSKU string                 `json:"supplier_sku"`
+	ComponentID int                    `json:"component_id,omitempty"`
+	Action      string                 `json:"action"`
+	Fields      map[string]FieldChange `json:"fields,omitempty"`
+}
+
+// ImportRowError - помилка розбору чи валідації рядка
+type ImportRowError struct {
+	Row         int               `json:"row"`
+	SupplierSKU string            `json:"supplier_sku,omitempty"`
+	Message     string            `json:"message"`
+	Fields      map[string]string `json:"fields,omitempty"`
+}
+
+// ImportReport - підсумок імпорту (при dry-run нічого не записано в БД)
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+	Total     int              `json:"total"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Changes   []ImportChange   `json:"changes"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+}
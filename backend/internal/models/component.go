@@ -10,3 +10,16 @@ type Component struct {
 	ImageURL string          `json:"image_url"` // <-- React шукає "image_url"
 	Specs    json.RawMessage `json:"specs"`     // <-- Це JSON всередині JSON
 }
+
+// ComponentDetail - сторінка компонента: характеристики, ціни, сумісні та схожі деталі
+type ComponentDetail struct {
+	Component
+	// Specs - розібрані характеристики (перекривають сирий JSON з Component)
+	Specs map[string]interface{} `json:"specs"`
+	// PriceSummary - поточна ціна і статистика за 30/90 днів (без самої історії)
+	PriceSummary PriceSummary `json:"price_summary"`
+	// CompatibleCounts - скільки деталей сумісні з цим компонентом, по категоріях, з якими є правило сумісності
+	CompatibleCounts map[string]int `json:"compatible_counts"`
+	// Similar - компоненти тієї ж категорії зі схожими характеристиками та ціною
+	Similar []Component `json:"similar"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// LoginAttempt - стан невдалих спроб входу для акаунта або IP
+type LoginAttempt struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time // Нульовий час - блокування немає
+}
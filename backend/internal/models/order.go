@@ -1,5 +1,25 @@
 package models
 
+import "time"
+
+// Статуси замовлення
+const (
+	OrderPending    = "pending"    // Нове, ще не оброблене
+	OrderProcessing = "processing" // Менеджер взяв у роботу
+	OrderShipped    = "shipped"    // Передано службі доставки
+	OrderDelivered  = "delivered"  // Отримано покупцем
+	OrderCancelled  = "cancelled"  // Скасовано
+)
+
+// IsValidOrderStatus - перевіряє, чи існує такий статус замовлення
+func IsValidOrderStatus(status string) bool {
+	switch status {
+	case OrderPending, OrderProcessing, OrderShipped, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
+}
+
 type OrderRequest struct {
 	CustomerName    string  `json:"customer_name"`
 	Phone           string  `json:"phone"`
@@ -9,3 +29,17 @@ type OrderRequest struct {
 	ComponentIDs    []int   `json:"component_ids"`
 	UserID          int     `json:"user_id,omitempty"`
 }
+
+// Order - збережене замовлення з усіма даними покупця (експорт персональних даних, адмінка)
+type Order struct {
+	ID              int       `json:"id"`
+	UserID          *int      `json:"user_id,omitempty"` // NULL - гість або знеособлене замовлення
+	CustomerName    string    `json:"customer_name"`
+	Phone           string    `json:"phone"`
+	DeliveryAddress string    `json:"delivery_address"`
+	PaymentMethod   string    `json:"payment_method"`
+	TotalPrice      float64   `json:"total_price"`
+	ComponentIDs    []int     `json:"component_ids"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+}
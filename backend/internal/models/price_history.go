@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PricePoint - ціна компонента, що діяла з моменту ChangedAt
+type PricePoint struct {
+	Price     float64   `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// PriceStats - мінімальна, середня та максимальна ціна за останні Days днів
+type PriceStats struct {
+	Days int     `json:"days"`
+	Min  float64 `json:"min"`
+	Avg  float64 `json:"avg"`
+	Max  float64 `json:"max"`
+}
+
+// PriceHistory - історія цін компонента з підсумком за 30/90 днів
+type PriceHistory struct {
+	ComponentID  int          `json:"component_id"`
+	CurrentPrice float64      `json:"current_price"`
+	History      []PricePoint `json:"history"`
+	Stats        []PriceStats `json:"stats"`
+	// IsGoodPrice - поточна ціна не вища за середню за 90 днів
+	IsGoodPrice bool `json:"is_good_price"`
+}
+
+// PriceSummary - підсумок цін без точок історії (для сторінки компонента)
+type PriceSummary struct {
+	CurrentPrice float64      `json:"current_price"`
+	Stats        []PriceStats `json:"stats"`
+	IsGoodPrice  bool         `json:"is_good_price"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RateLimit - token bucket: до Requests запитів поспіль, далі Requests запитів за Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateLimitResult - рішення щодо одного запиту
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int // Скільки запитів ще можна зробити одразу
+	// RetryAfter - через скільки з'явиться наступний токен (для відхиленого запиту)
+	RetryAfter time.Duration
+	// ResetAfter - через скільки відро знову буде повним
+	ResetAfter time.Duration
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RefreshToken - запис про виданий refresh-токен (сам токен у БД не зберігається)
+type RefreshToken struct {
+	ID        int64
+	UserID    int
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	// AuthTime - коли користувач увійшов (паролем, OIDC, 2FA); nil для сесій, старших за міграцію 000015
+	AuthTime *time.Time
+}
+
+// Призначення одноразових токенів, що надсилаються листом
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TwoFactor - стан двофакторної автентифікації користувача
+type TwoFactor struct {
+	// Secret - base32-секрет TOTP; порожній, якщо 2FA не підключали
+	Secret string
+	// EnabledAt - nil, доки користувач не підтвердив підключення першим кодом
+	EnabledAt *time.Time
+	// RecoveryCodesLeft - скільки невикористаних кодів відновлення залишилось
+	RecoveryCodesLeft int
+}
+
+// Enabled - чи потрібен другий фактор при вході
+func (t TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil && t.Secret != ""
+}
@@ -1,8 +1,29 @@
 package models
 
+import "time"
+
+// Ролі користувачів
+const (
+	RoleCustomer = "customer" // Звичайний покупець (за замовчуванням)
+	RoleManager  = "manager"  // Менеджер: обробка замовлень
+	RoleAdmin    = "admin"    // Адміністратор: керування каталогом і користувачами
+)
+
 type User struct {
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
 	Email    string `json:"email"` // Логін
 	Password string `json:"password"`
+	Role     string `json:"role"`
+
+	EmailVerifiedAt *time.Time `json:"-"`
+}
+
+// IsValidRole - перевіряє, чи існує така роль
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleManager, RoleAdmin:
+		return true
+	}
+	return false
 }
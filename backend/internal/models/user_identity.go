@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserIdentity - зовнішній обліковий запис (OpenID Connect), прив'язаний до користувача
+type UserIdentity struct {
+	UserID    int       `json:"-"`
+	Provider  string    `json:"provider"` // Назва провайдера з конфігурації, напр. "google"
+	Subject   string    `json:"subject"`  // Claim "sub" - незмінний ID користувача у провайдера
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Що саме відстежує користувач
+const (
+	WatchKindComponent = "component"
+	WatchKindBuild     = "build"
+)
+
+// Watch - відстеження ціни компонента або збірки з цільовою ціною
+type Watch struct {
+	ID           int     `json:"id"`
+	UserID       int     `json:"-"`
+	Kind         string  `json:"kind"`
+	Name         string  `json:"name"`
+	ComponentIDs []int   `json:"component_ids"`
+	TargetPrice  float64 `json:"target_price"`
+	// CurrentPrice - поточна сума цін; nil, якщо якогось компонента вже немає в каталозі
+	CurrentPrice      *float64  `json:"current_price"`
+	LastNotifiedPrice *float64  `json:"last_notified_price,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	// Заповнюється лише для фонового воркера (кому відправляти лист)
+	UserEmail string `json:"-"`
+}
+
+// Типи сповіщень
+const (
+	NotificationPriceDrop = "price_drop"
+)
+
+// Notification - внутрішнє сповіщення користувача
+type Notification struct {
+	ID        int64           `json:"id"`
+	UserID    int             `json:"-"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -2,11 +2,38 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/apperror"
 	"pc-configurator/internal/models"
 )
 
+var (
+	// ErrEmailTaken - користувач з таким email вже існує
+	ErrEmailTaken = &apperror.Error{
+		Kind:    apperror.ErrConflict,
+		Code:    "email_taken",
+		Message: "користувач з таким email вже існує",
+		Field:   "email",
+	}
+	ErrUserNotFound = apperror.NotFound("user_not_found", "користувача не знайдено")
+	// ErrTokenNotFound - refresh-токен або токен з листа не існує (чи вже недійсний)
+	ErrTokenNotFound = apperror.NotFound("token_not_found", "токен недійсний або протух")
+	// ErrTwoFactorStateChanged - стан 2FA змінив паралельний запит
+	ErrTwoFactorStateChanged = apperror.Conflict("two_factor_state_changed", "стан двофакторної автентифікації змінився, спробуйте ще раз")
+)
+
+// isUniqueViolation - Postgres відхилив запис через UNIQUE-обмеження.
+// Для users це email: точний збіг або той самий email в іншому регістрі (idx_users_email_lower).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 // AuthPostgres - це структура, яка вміє робити SQL запити до таблиці users
 type AuthPostgres struct {
 	db *sql.DB
@@ -21,26 +48,30 @@ func NewAuthPostgres(db *sql.DB) *AuthPostgres {
 func (r *AuthPostgres) CreateUser(user models.User) (int, error) {
 	var id int
 	// Запит на створення. RETURNING id повертає нам ID нового юзера.
-	query := "INSERT INTO users (name, email, password_hash) VALUES ($1, $2, $3) RETURNING id"
+	query := "INSERT INTO users (name, email, password_hash, role) VALUES ($1, $2, $3, $4) RETURNING id"
 
-	row := r.db.QueryRow(query, user.Name, user.Email, user.Password)
+	row := r.db.QueryRow(query, user.Name, user.Email, user.Password, user.Role)
 	if err := row.Scan(&id); err != nil {
-		return 0, fmt.Errorf("помилка додавання користувача (можливо email зайнятий): %w", err)
+		if isUniqueViolation(err) {
+			return 0, ErrEmailTaken
+		}
+		return 0, fmt.Errorf("помилка додавання користувача: %w", err)
 	}
 
 	return id, nil
 }
 
-// GetUserByEmail - Вхід (SELECT)
+// GetUserByEmail - Вхід (SELECT). Регістр не важливий: індекс idx_users_email_lower
+// унікальний за LOWER(email), тож підходить щонайбільше один користувач.
 func (r *AuthPostgres) GetUserByEmail(email string) (models.User, error) {
 	var user models.User
-	query := "SELECT id, name, email, password_hash FROM users WHERE email=$1"
+	query := "SELECT id, name, email, password_hash, role, email_verified_at FROM users WHERE LOWER(email)=LOWER($1)"
 
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Це не помилка сервера, це просто "невірний логін"
-			return user, fmt.Errorf("користувача з таким email не знайдено")
+			return user, ErrUserNotFound
 		}
 		return user, fmt.Errorf("помилка бази даних: %w", err)
 	}
@@ -51,12 +82,12 @@ func (r *AuthPostgres) GetUserByEmail(email string) (models.User, error) {
 // GetUserByID - Отримання користувача за ID
 func (r *AuthPostgres) GetUserByID(id int) (models.User, error) {
 	var user models.User
-	query := "SELECT id, name, email, password_hash FROM users WHERE id=$1"
+	query := "SELECT id, name, email, password_hash, role, email_verified_at FROM users WHERE id=$1"
 
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return user, fmt.Errorf("користувача не знайдено")
+			return user, ErrUserNotFound
 		}
 		return user, fmt.Errorf("помилка бази даних: %w", err)
 	}
@@ -64,14 +95,43 @@ func (r *AuthPostgres) GetUserByID(id int) (models.User, error) {
 	return user, nil
 }
 
-// UpdateProfile - Оновлення імені та email користувача
+// UpdateProfile - Оновлення імені та email користувача.
+// Якщо email змінився, його потрібно підтвердити заново, а листи з підтвердженням,
+// надіслані на стару адресу, стають недійсними - інакше вони підтвердили б нову.
 func (r *AuthPostgres) UpdateProfile(userID int, name, email string) error {
-	query := "UPDATE users SET name=$1, email=$2 WHERE id=$3"
-	_, err := r.db.Exec(query, name, email, userID)
+	tx, err := r.db.Begin()
 	if err != nil {
+		return fmt.Errorf("помилка бази даних: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldEmail string
+	if err := tx.QueryRow("SELECT email FROM users WHERE id=$1 FOR UPDATE", userID).Scan(&oldEmail); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("помилка бази даних: %w", err)
+	}
+
+	query := `UPDATE users SET name=$1, email=$2,
+		email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+		WHERE id=$3`
+	if _, err := tx.Exec(query, name, email, userID); err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailTaken
+		}
 		return fmt.Errorf("помилка оновлення профілю: %w", err)
 	}
-	return nil
+
+	if oldEmail != email {
+		_, err := tx.Exec("UPDATE user_tokens SET used_at = NOW() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL",
+			userID, models.TokenPurposeVerifyEmail)
+		if err != nil {
+			return fmt.Errorf("помилка анулювання старих токенів: %w", err)
+		}
+	}
+
+	return tx.Commit()
 }
 
 // UpdatePassword - Оновлення пароля користувача
@@ -83,3 +143,353 @@ func (r *AuthPostgres) UpdatePassword(userID int, newPasswordHash string) error
 	}
 	return nil
 }
+
+// UpdateRole - Зміна ролі користувача
+func (r *AuthPostgres) UpdateRole(userID int, role string) error {
+	query := "UPDATE users SET role=$1 WHERE id=$2"
+	res, err := r.db.Exec(query, role, userID)
+	if err != nil {
+		return fmt.Errorf("помилка оновлення ролі: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// CreateRefreshToken - зберігає хеш нового refresh-токена
+func (r *AuthPostgres) CreateRefreshToken(userID int, tokenHash string, expiresAt, authTime time.Time) error {
+	query := "INSERT INTO refresh_tokens (user_id, token_hash, expires_at, auth_time) VALUES ($1, $2, $3, $4)"
+	if _, err := r.db.Exec(query, userID, tokenHash, expiresAt, authTime); err != nil {
+		return fmt.Errorf("помилка збереження refresh-токена: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshToken - пошук refresh-токена за хешем (включно з відкликаними)
+func (r *AuthPostgres) GetRefreshToken(tokenHash string) (models.RefreshToken, error) {
+	var t models.RefreshToken
+	query := "SELECT id, user_id, expires_at, revoked_at, auth_time FROM refresh_tokens WHERE token_hash=$1"
+
+	err := r.db.QueryRow(query, tokenHash).Scan(&t.ID, &t.UserID, &t.ExpiresAt, &t.RevokedAt, &t.AuthTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return t, ErrTokenNotFound
+		}
+		return t, fmt.Errorf("помилка бази даних: %w", err)
+	}
+
+	return t, nil
+}
+
+// RevokeRefreshToken - відкликає токен. Повертає false, якщо його вже відкликали
+// (наприклад, паралельний запит встиг використати той самий токен)
+func (r *AuthPostgres) RevokeRefreshToken(tokenHash string) (bool, error) {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash=$1 AND revoked_at IS NULL"
+	res, err := r.db.Exec(query, tokenHash)
+	if err != nil {
+		return false, fmt.Errorf("помилка відкликання refresh-токена: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RevokeAllRefreshTokens - завершує всі сесії користувача
+func (r *AuthPostgres) RevokeAllRefreshTokens(userID int) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id=$1 AND revoked_at IS NULL"
+	if _, err := r.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("помилка відкликання сесій: %w", err)
+	}
+	return nil
+}
+
+// CreateUserToken - зберігає хеш одноразового токена (підтвердження email, скидання пароля).
+// Попередні невикористані токени з тим самим призначенням стають недійсними.
+func (r *AuthPostgres) CreateUserToken(userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("помилка бази даних: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE user_tokens SET used_at = NOW() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL", userID, purpose)
+	if err != nil {
+		return fmt.Errorf("помилка анулювання старих токенів: %w", err)
+	}
+
+	query := "INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)"
+	if _, err := tx.Exec(query, userID, purpose, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("помилка збереження токена: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeUserToken - атомарно позначає токен використаним і повертає ID користувача.
+// Протухлий, використаний або чужий (інше призначення) токен не знаходиться.
+func (r *AuthPostgres) ConsumeUserToken(purpose, tokenHash string) (int, error) {
+	query := `UPDATE user_tokens SET used_at = NOW()
+		WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID int
+	if err := r.db.QueryRow(query, tokenHash, purpose).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrTokenNotFound
+		}
+		return 0, fmt.Errorf("помилка бази даних: %w", err)
+	}
+
+	return userID, nil
+}
+
+// MarkEmailVerified - позначає email користувача підтвердженим
+func (r *AuthPostgres) MarkEmailVerified(userID int) error {
+	query := "UPDATE users SET email_verified_at = NOW() WHERE id=$1 AND email_verified_at IS NULL"
+	if _, err := r.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("помилка підтвердження email: %w", err)
+	}
+	return nil
+}
+
+// ErrIdentityNotFound - зовнішній обліковий запис ще не прив'язаний до жодного користувача
+var ErrIdentityNotFound = apperror.NotFound("identity_not_found", "зовнішній обліковий запис не прив'язаний")
+
+// ErrIdentityTaken - (provider, subject) уже прив'язано, напр. паралельним входом через провайдера
+var ErrIdentityTaken = apperror.Conflict("identity_taken", "цей обліковий запис провайдера вже прив'язано, спробуйте увійти ще раз")
+
+// GetUserByIdentity - користувач, до якого прив'язано (provider, subject)
+func (r *AuthPostgres) GetUserByIdentity(provider, subject string) (models.User, error) {
+	var user models.User
+	query := `SELECT u.id, u.name, u.email, u.password_hash, u.role, u.email_verified_at
+		FROM user_identities i JOIN users u ON u.id = i.user_id
+		WHERE i.provider=$1 AND i.subject=$2`
+
+	err := r.db.QueryRow(query, provider, subject).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, ErrIdentityNotFound
+		}
+		return user, fmt.Errorf("помилка бази даних: %w", err)
+	}
+
+	return user, nil
+}
+
+// CreateIdentity - прив'язує зовнішній обліковий запис до існуючого користувача
+func (r *AuthPostgres) CreateIdentity(identity models.UserIdentity) error {
+	query := "INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)"
+	if _, err := r.db.Exec(query, identity.UserID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		if isUniqueViolation(err) {
+			return ErrIdentityTaken
+		}
+		return fmt.Errorf("помилка прив'язки облікового запису: %w", err)
+	}
+	return nil
+}
+
+// CreateUserWithIdentity - реєстрація через зовнішнього провайдера: користувач
+// без прив'язки (або прив'язка без користувача) не повинен лишитись у БД
+func (r *AuthPostgres) CreateUserWithIdentity(user models.User, identity models.UserIdentity) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := `INSERT INTO users (name, email, password_hash, role, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	if err := tx.QueryRow(query, user.Name, user.Email, user.Password, user.Role, user.EmailVerifiedAt).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrEmailTaken
+		}
+		return 0, fmt.Errorf("помилка додавання користувача: %w", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)",
+		id, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrIdentityTaken
+		}
+		return 0, fmt.Errorf("помилка прив'язки облікового запису: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetUserIdentities - усі зовнішні облікові записи користувача
+func (r *AuthPostgres) GetUserIdentities(userID int) ([]models.UserIdentity, error) {
+	query := "SELECT user_id, provider, subject, email, created_at FROM user_identities WHERE user_id=$1 ORDER BY id"
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("помилка бази даних: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var i models.UserIdentity
+		if err := rows.Scan(&i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+
+	return identities, rows.Err()
+}
+
+// DeleteUser - знеособлює замовлення і видаляє користувача; пов'язані записи видаляє каскад у БД
+func (r *AuthPostgres) DeleteUser(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("помилка бази даних: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Спершу замовлення: після видалення користувача їх уже не знайти за user_id
+	if err := anonymizeUserOrders(tx, userID); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("DELETE FROM users WHERE id=$1", userID)
+	if err != nil {
+		return fmt.Errorf("помилка видалення користувача: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+// GetTwoFactor - секрет TOTP, чи увімкнено 2FA і скільки лишилось кодів відновлення
+func (r *AuthPostgres) GetTwoFactor(userID int) (models.TwoFactor, error) {
+	var tf models.TwoFactor
+	var secret sql.NullString
+	query := `SELECT u.totp_secret, u.totp_enabled_at,
+		(SELECT COUNT(*) FROM recovery_codes rc WHERE rc.user_id = u.id AND rc.used_at IS NULL)
+		FROM users u WHERE u.id=$1`
+
+	err := r.db.QueryRow(query, userID).Scan(&secret, &tf.EnabledAt, &tf.RecoveryCodesLeft)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tf, ErrUserNotFound
+		}
+		return tf, fmt.Errorf("помилка бази даних: %w", err)
+	}
+	tf.Secret = secret.String
+
+	return tf, nil
+}
+
+// SetPendingTOTPSecret - зберігає новий секрет; увімкнену 2FA так не перезаписати
+func (r *AuthPostgres) SetPendingTOTPSecret(userID int, secret string) error {
+	query := "UPDATE users SET totp_secret=$2, totp_last_step=NULL WHERE id=$1 AND totp_enabled_at IS NULL"
+	res, err := r.db.Exec(query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("помилка збереження секрету 2FA: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTwoFactorStateChanged
+	}
+	return nil
+}
+
+// EnableTwoFactor - вмикає 2FA з уже збереженим секретом і записує нові коди відновлення
+func (r *AuthPostgres) EnableTwoFactor(userID int, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE users SET totp_enabled_at = NOW()
+		WHERE id=$1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("помилка увімкнення 2FA: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTwoFactorStateChanged
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableTwoFactor - вимикає 2FA і видаляє коди відновлення
+func (r *AuthPostgres) DisableTwoFactor(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=NULL WHERE id=$1", userID)
+	if err != nil {
+		return fmt.Errorf("помилка вимкнення 2FA: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id=$1", userID); err != nil {
+		return fmt.Errorf("помилка видалення кодів відновлення: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes - нові коди відновлення замість усіх старих
+func (r *AuthPostgres) ReplaceRecoveryCodes(userID int, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int, hashes []string) error {
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id=$1", userID); err != nil {
+		return fmt.Errorf("помилка видалення кодів відновлення: %w", err)
+	}
+	_, err := tx.Exec(`INSERT INTO recovery_codes (user_id, code_hash)
+		SELECT $1, h FROM unnest($2::text[]) AS h`, userID, pq.Array(hashes))
+	if err != nil {
+		return fmt.Errorf("помилка збереження кодів відновлення: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep - атомарно запам'ятовує використаний інтервал TOTP
+func (r *AuthPostgres) UseTOTPStep(userID int, step int64) (bool, error) {
+	query := "UPDATE users SET totp_last_step=$2 WHERE id=$1 AND (totp_last_step IS NULL OR totp_last_step < $2)"
+	res, err := r.db.Exec(query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("помилка бази даних: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ConsumeRecoveryCode - позначає код відновлення використаним
+func (r *AuthPostgres) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	query := "UPDATE recovery_codes SET used_at = NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL"
+	res, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("помилка бази даних: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/models"
+)
+
+// existingComponent - поточний стан компонента з тим самим supplier_sku
+type existingComponent struct {
+	models.Component
+	Deleted bool
+}
+
+// ImportComponents - upsert компонентів за supplier_sku в одній транзакції.
+// Спочатку рахує diff відносно поточного стану, потім (якщо не dryRun) записує зміни.
+// Для dryRun транзакція відкочується, тож звіт відповідає реальному стану БД.
+func (r *ComponentRepo) ImportComponents(ctx context.Context, items []models.ImportItem, dryRun bool) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: dryRun, Total: len(items), Changes: []models.ImportChange{}}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return report, fmt.Errorf("помилка початку транзакції: %w", err)
+	}
+	defer tx.Rollback()
+
+	skus := make([]string, len(items))
+	for i, item := range items {
+		skus[i] = item.SupplierSKU
+	}
+
+	// Блокуємо рядки, щоб паралельний імпорт не перезаписав їх між diff і upsert
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, supplier_sku, name, category, price, image_url, specs, deleted_at IS NOT NULL
+		FROM components
+		WHERE supplier_sku = ANY($1)
+		FOR UPDATE`, pq.Array(skus))
+	if err != nil {
+		return report, fmt.Errorf("помилка читання існуючих компонентів: %w", err)
+	}
+
+	existing := make(map[string]existingComponent)
+	for rows.Next() {
+		var e existingComponent
+		var sku string
+		if err := rows.Scan(&e.ID, &sku, &e.Name, &e.Category, &e.Price, &e.ImageURL, &e.Specs, &e.Deleted); err != nil {
+			rows.Close()
+			return report, err
+		}
+		existing[sku] = e
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	for _, item := range items {
+		change := models.ImportChange{SupplierSKU: item.SupplierSKU}
+
+		old, found := existing[item.SupplierSKU]
+		if !found {
+			change.Action = models.ImportActionCreate
+			report.Created++
+		} else {
+			change.ComponentID = old.ID
+			change.Fields = diffComponent(old, item.Component)
+			if len(change.Fields) == 0 {
+				change.Action = models.ImportActionUnchanged
+				report.Unchanged++
+				report.Changes = append(report.Changes, change)
+				continue
+			}
+			change.Action = models.ImportActionUpdate
+			report.Updated++
+		}
+
+		if !dryRun {
+			c := item.Component
+			// Якщо компонент був м'яко видалений, але знову є у прайсі — повертаємо його в каталог
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO components (supplier_sku, name, category, price, image_url, specs)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (supplier_sku) DO UPDATE SET
+					name = EXCLUDED.name,
+					category = EXCLUDED.category,
+					price = EXCLUDED.price,
+					image_url = EXCLUDED.image_url,
+					specs = EXCLUDED.specs,
+					deleted_at = NULL
+				RETURNING id`,
+				item.SupplierSKU, c.Name, c.Category, c.Price, c.ImageURL, []byte(c.Specs),
+			).Scan(&change.ComponentID)
+			if err != nil {
+				return report, fmt.Errorf("помилка імпорту SKU %s: %w", item.SupplierSKU, err)
+			}
+		}
+
+		report.Changes = append(report.Changes, change)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return report, fmt.Errorf("помилка фіксації імпорту: %w", err)
+	}
+	report.Applied = true
+
+	return report, nil
+}
+
+// diffComponent - перелік полів, що відрізняються між станом у БД і прайсом
+func diffComponent(old existingComponent, c models.Component) map[string]models.FieldChange {
+	fields := map[string]models.FieldChange{}
+
+	if old.Name != c.Name {
+		fields["name"] = models.FieldChange{Old: old.Name, New: c.Name}
+	}
+	if old.Category != c.Category {
+		fields["category"] = models.FieldChange{Old: old.Category, New: c.Category}
+	}
+	// Ціна в БД - NUMERIC(10, 2): 1299.999 з прайсу збережеться як 1300.00,
+	// тож порівнюємо копійки, інакше кожен повторний імпорт бачив би зміну
+	if cents(old.Price) != cents(c.Price) {
+		fields["price"] = models.FieldChange{Old: old.Price, New: c.Price}
+	}
+	if old.ImageURL != c.ImageURL {
+		fields["image_url"] = models.FieldChange{Old: old.ImageURL, New: c.ImageURL}
+	}
+	if !jsonEqual(old.Specs, c.Specs) {
+		fields["specs"] = models.FieldChange{Old: old.Specs, New: c.Specs}
+	}
+	if old.Deleted {
+		fields["deleted"] = models.FieldChange{Old: true, New: false}
+	}
+
+	return fields
+}
+
+func cents(price float64) int64 {
+	return int64(math.Round(price * 100))
+}
+
+// jsonEqual - порівнює два JSON за змістом (без урахування порядку ключів і пробілів)
+func jsonEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"pc-configurator/internal/models"
+)
+
+func TestDiffComponent(t *testing.T) {
+	stored := existingComponent{Component: models.Component{
+		ID: 7, Name: "Ryzen 5 7600", Category: "cpu", Price: 8999.9, ImageURL: "https://img.test/7600.png",
+		Specs: json.RawMessage(`{"socket": "AM5", "tdp": 65}`),
+	}}
+
+	tests := []struct {
+		name   string
+		edit   func(c *models.Component)
+		old    func(e *existingComponent)
+		fields []string
+	}{
+		{"same", nil, nil, nil},
+		{"specs key order and spacing", func(c *models.Component) { c.Specs = json.RawMessage(`{"tdp":65,"socket":"AM5"}`) }, nil, nil},
+		// NUMERIC(10, 2) у БД: різниця менша за копійку - не зміна
+		{"price within a cent", func(c *models.Component) { c.Price = 8999.9000001 }, nil, nil},
+		{"price rounds to the stored value", func(c *models.Component) { c.Price = 8999.899 }, nil, nil},
+		{"price", func(c *models.Component) { c.Price = 8999.89 }, nil, []string{"price"}},
+		{"name", func(c *models.Component) { c.Name = "Ryzen 5 7600X" }, nil, []string{"name"}},
+		{"specs value", func(c *models.Component) { c.Specs = json.RawMessage(`{"socket":"AM5","tdp":105}`) }, nil, []string{"specs"}},
+		{"several fields", func(c *models.Component) { c.Category = "gpu"; c.ImageURL = "" }, nil, []string{"category", "image_url"}},
+		{"restored after soft delete", nil, func(e *existingComponent) { e.Deleted = true }, []string{"deleted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, c := stored, stored.Component
+			if tt.edit != nil {
+				tt.edit(&c)
+			}
+			if tt.old != nil {
+				tt.old(&old)
+			}
+
+			var fields []string
+			for f := range diffComponent(old, c) {
+				fields = append(fields, f)
+			}
+			slices.Sort(fields)
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("changed fields %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+
+	if got := diffComponent(stored, models.Component{Name: "Ryzen 5 7600", Category: "cpu", Price: 9100, ImageURL: stored.ImageURL, Specs: stored.Specs})["price"]; got.Old != 8999.9 || got.New != 9100.0 {
+		t.Errorf("price change = %+v", got)
+	}
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+)
+
+// componentsChannel - канал NOTIFY, у який пише тригер міграції 000013
+const componentsChannel = "components_changed"
+
+// ComponentCache - декоратор ComponentRepository, що тримає весь каталог у пам'яті.
+// Читання (GetAll, GetByID, GetByIDs) обслуговуються зі знімка каталогу без запитів до БД,
+// запис іде в next і скидає знімок. Знімок перечитується, якщо він старший за ttl
+// або його скинуто (власним записом чи ComponentChangeListener).
+type ComponentCache struct {
+	next ComponentRepository
+	ttl  time.Duration
+
+	loadMu sync.Mutex // Каталог перечитує лише один запит, решта чекають на його результат
+
+	mu   sync.RWMutex
+	snap *catalogSnapshot
+	gen  uint64 // Збільшується при кожному скиданні
+}
+
+// catalogSnapshot - незмінний знімок каталогу; читачі користуються ним без блокувань
+type catalogSnapshot struct {
+	items    []models.Component // Як з БД: за зростанням ціни
+	names    []string           // Назви в нижньому регістрі для пошуку
+	byID     map[int]int        // ID -> індекс у items
+	loadedAt time.Time
+	gen      uint64 // gen кешу на момент початку завантаження
+}
+
+func NewComponentCache(next ComponentRepository, ttl time.Duration) *ComponentCache {
+	return &ComponentCache{next: next, ttl: ttl}
+}
+
+// Invalidate - наступне читання перечитає каталог з БД
+func (c *ComponentCache) Invalidate() {
+	c.mu.Lock()
+	c.gen++
+	c.mu.Unlock()
+}
+
+// GetAll - ті самі фільтри, що й у ComponentRepo.GetAll, але по знімку в пам'яті
+func (c *ComponentCache) GetAll(ctx context.Context, category string, minPrice, maxPrice float64, search string, sort string) ([]models.Component, error) {
+	snap, err := c.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search = strings.ToLower(search)
+
+	var components []models.Component
+	for i, comp := range snap.items {
+		if category != "" && comp.Category != category {
+			continue
+		}
+		if minPrice > 0 && comp.Price < minPrice {
+			continue
+		}
+		if maxPrice > 0 && comp.Price > maxPrice {
+			continue
+		}
+		if search != "" && !strings.Contains(snap.names[i], search) {
+			continue
+		}
+		components = append(components, comp)
+	}
+
+	if sort == "desc" {
+		slices.Reverse(components)
+	}
+
+	return components, nil
+}
+
+func (c *ComponentCache) GetByID(ctx context.Context, id int) (*models.Component, error) {
+	snap, err := c.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := snap.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w (id %d)", ErrComponentNotFound, id)
+	}
+	comp := snap.items[i]
+	return &comp, nil
+}
+
+func (c *ComponentCache) GetByIDs(ctx context.Context, ids []int) ([]models.Component, error) {
+	snap, err := c.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderComponents(ids, func(id int) (models.Component, bool) {
+		i, ok := snap.byID[id]
+		if !ok {
+			return models.Component{}, false
+		}
+		return snap.items[i], true
+	})
+}
+
+func (c *ComponentCache) Create(ctx context.Context, comp models.Component) (int, error) {
+	defer c.Invalidate()
+	return c.next.Create(ctx, comp)
+}
+
+func (c *ComponentCache) Update(ctx context.Context, comp models.Component) error {
+	defer c.Invalidate()
+	return c.next.Update(ctx, comp)
+}
+
+func (c *ComponentCache) Delete(ctx context.Context, id int) error {
+	defer c.Invalidate()
+	return c.next.Delete(ctx, id)
+}
+
+// snapshot - актуальний знімок; за потреби перечитує каталог.
+// Якщо БД недоступна, а старий знімок є - віддаємо його, щоб каталог не падав разом з БД.
+func (c *ComponentCache) snapshot(ctx context.Context) (*catalogSnapshot, error) {
+	if snap, ok := c.current(); ok {
+		return snap, nil
+	}
+
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	// Поки чекали, каталог міг перечитати інший запит
+	snap, ok := c.current()
+	if ok {
+		return snap, nil
+	}
+
+	c.mu.RLock()
+	gen := c.gen
+	c.mu.RUnlock()
+
+	items, err := c.next.GetAll(ctx, "", 0, 0, "", "")
+	switch {
+	case err == nil:
+		snap = newCatalogSnapshot(items, gen)
+	case snap == nil:
+		return nil, err
+	default:
+		logging.FromContext(ctx).Warn("catalog reload failed, serving stale catalog", "error", err)
+		// Наступна спроба - через ttl (або після скидання), а не на кожен запит
+		stale := *snap
+		stale.loadedAt, stale.gen = time.Now(), gen
+		snap = &stale
+	}
+
+	c.mu.Lock()
+	c.snap = snap
+	c.mu.Unlock()
+
+	return snap, nil
+}
+
+// current - поточний знімок і чи він ще актуальний
+func (c *ComponentCache) current() (*catalogSnapshot, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.snap == nil {
+		return nil, false
+	}
+	// Скидання під час завантаження лишає знімок застарілим - зміну не буде втрачено
+	return c.snap, c.snap.gen == c.gen && time.Since(c.snap.loadedAt) < c.ttl
+}
+
+// newCatalogSnapshot - specs розбираються один раз при завантаженні:
+// коректний JSON зберігається стиснутим, некоректний замінюється на {},
+// тож сервіси, що читають specs, не натрапляють на зіпсовані рядки
+func newCatalogSnapshot(items []models.Component, gen uint64) *catalogSnapshot {
+	snap := &catalogSnapshot{
+		items:    items,
+		names:    make([]string, len(items)),
+		byID:     make(map[int]int, len(items)),
+		loadedAt: time.Now(),
+		gen:      gen,
+	}
+
+	for i := range items {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, items[i].Specs); err == nil {
+			items[i].Specs = buf.Bytes()
+		} else {
+			items[i].Specs = json.RawMessage(`{}`)
+		}
+		snap.names[i] = strings.ToLower(items[i].Name)
+		snap.byID[items[i].ID] = i
+	}
+
+	return snap
+}
+
+// ComponentChangeListener - слухає NOTIFY components_changed і скидає кеш каталогу,
+// тож зміни з адмінки, імпорту прайсу чи іншого інстансу видно одразу, а не через ttl
+type ComponentChangeListener struct {
+	dsn   string
+	cache *ComponentCache
+}
+
+func NewComponentChangeListener(dsn string, cache *ComponentCache) *ComponentChangeListener {
+	return &ComponentChangeListener{dsn: dsn, cache: cache}
+}
+
+// listenerPingInterval - як часто перевіряти з'єднання, коли сповіщень немає
+const listenerPingInterval = 90 * time.Second
+
+// Run - слухає канал, доки не скасовано ctx. Обрив з'єднання pq.Listener відновлює сам.
+func (l *ComponentChangeListener) Run(ctx context.Context) {
+	log := logging.FromContext(ctx)
+
+	listener := pq.NewListener(l.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn("catalog listener connection problem", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(componentsChannel); err != nil {
+		log.Error("catalog listener failed, cache refreshes by TTL only", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+			// nil приходить після перепідключення: сповіщення за цей час могли загубитися,
+			// тож кеш скидаємо в будь-якому разі
+			l.cache.Invalidate()
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
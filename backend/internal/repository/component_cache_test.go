@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// fakeComponents - ComponentRepository у пам'яті, рахує перечитування каталогу
+type fakeComponents struct {
+	items  map[int]models.Component
+	nextID int
+	loads  int
+	err    error  // Помилка для GetAll
+	onLoad func() // Викликається посеред GetAll
+}
+
+func newFakeComponents(items ...models.Component) *fakeComponents {
+	f := &fakeComponents{items: map[int]models.Component{}}
+	for _, c := range items {
+		f.Create(context.Background(), c)
+	}
+	return f
+}
+
+func (f *fakeComponents) GetAll(ctx context.Context, category string, minPrice, maxPrice float64, search string, sort string) ([]models.Component, error) {
+	f.loads++
+	if f.onLoad != nil {
+		f.onLoad()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var list []models.Component
+	for _, c := range f.items {
+		list = append(list, c)
+	}
+	slices.SortFunc(list, func(a, b models.Component) int {
+		if a.Price != b.Price {
+			return int(a.Price - b.Price)
+		}
+		return a.ID - b.ID
+	})
+	return list, nil
+}
+
+func (f *fakeComponents) GetByID(ctx context.Context, id int) (*models.Component, error) {
+	panic("cache must serve GetByID from its snapshot")
+}
+
+func (f *fakeComponents) GetByIDs(ctx context.Context, ids []int) ([]models.Component, error) {
+	panic("cache must serve GetByIDs from its snapshot")
+}
+
+func (f *fakeComponents) Create(ctx context.Context, c models.Component) (int, error) {
+	f.nextID++
+	c.ID = f.nextID
+	f.items[c.ID] = c
+	return c.ID, nil
+}
+
+func (f *fakeComponents) Update(ctx context.Context, c models.Component) error {
+	if _, ok := f.items[c.ID]; !ok {
+		return ErrComponentNotFound
+	}
+	f.items[c.ID] = c
+	return nil
+}
+
+func (f *fakeComponents) Delete(ctx context.Context, id int) error {
+	delete(f.items, id)
+	return nil
+}
+
+func testCatalog() *fakeComponents {
+	return newFakeComponents(
+		models.Component{Name: "Ryzen 5 7600", Category: "cpu", Price: 9000, Specs: json.RawMessage(`{ "socket": "AM5" }`)},
+		models.Component{Name: "Core i5-13400F", Category: "cpu", Price: 8000, Specs: json.RawMessage(`{"socket":"LGA1700"}`)},
+		models.Component{Name: "B650 Tomahawk", Category: "motherboard", Price: 10000, Specs: json.RawMessage(`not json`)},
+	)
+}
+
+func TestComponentCacheReads(t *testing.T) {
+	ctx := context.Background()
+	repo := testCatalog()
+	cache := NewComponentCache(repo, time.Hour)
+
+	cpus, err := cache.GetAll(ctx, "cpu", 0, 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cpus) != 2 || cpus[0].ID != 2 || cpus[1].ID != 1 {
+		t.Errorf("cpu by price: %+v", cpus)
+	}
+
+	tests := []struct {
+		name               string
+		category, search   string
+		minPrice, maxPrice float64
+		sort               string
+		want               []int
+	}{
+		{"all", "", "", 0, 0, "", []int{2, 1, 3}},
+		{"desc", "", "", 0, 0, "desc", []int{3, 1, 2}},
+		{"price range", "", "", 8500, 9500, "", []int{1}},
+		{"search ignores case", "", "TOMAHAWK", 0, 0, "", []int{3}},
+		{"nothing", "gpu", "", 0, 0, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.GetAll(ctx, tt.category, tt.minPrice, tt.maxPrice, tt.search, tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, c := range got {
+				ids = append(ids, c.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("ids %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	byIDs, err := cache.GetByIDs(ctx, []int{3, 1, 3})
+	if err != nil || len(byIDs) != 3 || byIDs[0].ID != 3 || byIDs[1].ID != 1 || byIDs[2].ID != 3 {
+		t.Errorf("GetByIDs = %+v, %v", byIDs, err)
+	}
+	if _, err := cache.GetByIDs(ctx, []int{1, 42}); !errors.Is(err, ErrComponentNotFound) {
+		t.Errorf("GetByIDs with unknown id: err = %v", err)
+	}
+	if _, err := cache.GetByID(ctx, 42); !errors.Is(err, ErrComponentNotFound) {
+		t.Errorf("GetByID(42): err = %v", err)
+	}
+
+	// specs стиснуто, зіпсований JSON замінено на {}
+	if c, _ := cache.GetByID(ctx, 1); string(c.Specs) != `{"socket":"AM5"}` {
+		t.Errorf("specs = %s", c.Specs)
+	}
+	if c, _ := cache.GetByID(ctx, 3); string(c.Specs) != `{}` {
+		t.Errorf("broken specs = %s", c.Specs)
+	}
+
+	if repo.loads != 1 {
+		t.Errorf("catalog loaded %d times, want 1", repo.loads)
+	}
+}
+
+func TestComponentCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	repo := testCatalog()
+	cache := NewComponentCache(repo, time.Hour)
+
+	priceOf := func(id int) float64 {
+		t.Helper()
+		c, err := cache.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Price
+	}
+	priceOf(1)
+
+	// Запис через кеш скидає знімок
+	if err := cache.Update(ctx, models.Component{ID: 1, Name: "Ryzen 5 7600", Category: "cpu", Price: 8500}); err != nil {
+		t.Fatal(err)
+	}
+	if p := priceOf(1); p != 8500 {
+		t.Errorf("price after Update = %v", p)
+	}
+
+	id, err := cache.Create(ctx, models.Component{Name: "RTX 4060", Category: "gpu", Price: 13000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := priceOf(id); p != 13000 {
+		t.Errorf("created component price = %v", p)
+	}
+
+	if err := cache.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetByID(ctx, id); !errors.Is(err, ErrComponentNotFound) {
+		t.Errorf("deleted component: err = %v", err)
+	}
+
+	// Зміна повз кеш (імпорт, інший інстанс) видна після Invalidate від слухача NOTIFY
+	repo.items[2] = models.Component{ID: 2, Name: "Core i5-13400F", Category: "cpu", Price: 7000}
+	if p := priceOf(2); p != 8000 {
+		t.Errorf("price before Invalidate = %v, want the cached 8000", p)
+	}
+	cache.Invalidate()
+	if p := priceOf(2); p != 7000 {
+		t.Errorf("price after Invalidate = %v", p)
+	}
+
+	if repo.loads != 5 {
+		t.Errorf("catalog loaded %d times, want 5", repo.loads)
+	}
+}
+
+func TestComponentCacheInvalidateDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	repo := testCatalog()
+	cache := NewComponentCache(repo, time.Hour)
+
+	// Скидання посеред завантаження: знімок міг пропустити зміну, тож він одразу застарілий
+	repo.onLoad = func() {
+		repo.onLoad = nil
+		cache.Invalidate()
+	}
+	if _, err := cache.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if repo.loads != 2 {
+		t.Errorf("catalog loaded %d times, want 2", repo.loads)
+	}
+}
+
+func TestComponentCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := testCatalog()
+	cache := NewComponentCache(repo, time.Hour)
+
+	cache.GetByID(ctx, 1)
+	cache.snap.loadedAt = time.Now().Add(-2 * time.Hour)
+	cache.GetByID(ctx, 1)
+
+	if repo.loads != 2 {
+		t.Errorf("catalog loaded %d times, want 2", repo.loads)
+	}
+}
+
+func TestComponentCacheStaleOnError(t *testing.T) {
+	ctx := context.Background()
+	repo := testCatalog()
+	repo.err = errors.New("connection refused")
+	cache := NewComponentCache(repo, time.Hour)
+
+	// Знімка ще немає - помилку нема чим прикрити
+	if _, err := cache.GetByID(ctx, 1); !errors.Is(err, repo.err) {
+		t.Fatalf("err = %v, want the load error", err)
+	}
+
+	repo.err = nil
+	cache.GetByID(ctx, 1)
+
+	// БД впала - віддаємо старий знімок і не перечитуємо його на кожен запит
+	repo.err = errors.New("connection refused")
+	cache.Invalidate()
+	for range 3 {
+		if c, err := cache.GetByID(ctx, 1); err != nil || c.Price != 9000 {
+			t.Fatalf("stale read = %+v, %v", c, err)
+		}
+	}
+	if repo.loads != 3 {
+		t.Errorf("catalog loaded %d times, want 3", repo.loads)
+	}
+
+	// Наступне скидання знову пробує БД
+	repo.err = nil
+	repo.items[1] = models.Component{ID: 1, Name: "Ryzen 5 7600", Category: "cpu", Price: 8800}
+	cache.Invalidate()
+	if c, _ := cache.GetByID(ctx, 1); c.Price != 8800 {
+		t.Errorf("price after recovery = %v", c.Price)
+	}
+}
@@ -5,12 +5,18 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/apperror"
 	"pc-configurator/internal/models"
 )
 
 // УВАГА: Інтерфейс ми видалили звідси, бо він є в repository.go
 // Тут залишається тільки реалізація (struct)
 
+// ErrComponentNotFound - компонента немає в каталозі (або його видалено)
+var ErrComponentNotFound = apperror.NotFound("component_not_found", "компонент не знайдено")
+
 // ComponentRepo - реалізація інтерфейсу (struct)
 type ComponentRepo struct {
 	db *sql.DB
@@ -24,7 +30,8 @@ func NewComponentRepository(db *sql.DB) *ComponentRepo {
 // GetAll повертає список компонентів. Якщо category не пуста — фільтрує.
 func (r *ComponentRepo) GetAll(ctx context.Context, category string, minPrice, maxPrice float64, search string, sort string) ([]models.Component, error) {
 	// Базовий запит (1=1 дозволяє легко додавати AND)
-	query := `SELECT id, name, category, price, image_url, specs FROM components WHERE 1=1`
+	// Видалені (deleted_at IS NOT NULL) компоненти в каталог не потрапляють
+	query := `SELECT id, name, category, price, image_url, specs FROM components WHERE deleted_at IS NULL`
 	args := []interface{}{}
 	argId := 1
 
@@ -83,7 +90,7 @@ func (r *ComponentRepo) GetAll(ctx context.Context, category string, minPrice, m
 
 // GetByID повертає один компонент за його ID
 func (r *ComponentRepo) GetByID(ctx context.Context, id int) (*models.Component, error) {
-	query := `SELECT id, name, category, price, image_url, specs FROM components WHERE id = $1`
+	query := `SELECT id, name, category, price, image_url, specs FROM components WHERE id = $1 AND deleted_at IS NULL`
 
 	var c models.Component
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -92,10 +99,96 @@ func (r *ComponentRepo) GetByID(ctx context.Context, id int) (*models.Component,
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("компонент з id %d не знайдено", id)
+			return nil, fmt.Errorf("%w (id %d)", ErrComponentNotFound, id)
 		}
 		return nil, fmt.Errorf("помилка отримання компонента: %w", err)
 	}
 
 	return &c, nil
 }
+
+// GetByIDs повертає компоненти збірки одним запитом, у порядку ids
+func (r *ComponentRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Component, error) {
+	query := `SELECT id, name, category, price, image_url, specs FROM components WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(toInt64s(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання компонентів: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[int]models.Component, len(ids))
+	for rows.Next() {
+		var c models.Component
+		if err := rows.Scan(&c.ID, &c.Name, &c.Category, &c.Price, &c.ImageURL, &c.Specs); err != nil {
+			return nil, err
+		}
+		found[c.ID] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orderComponents(ids, func(id int) (models.Component, bool) {
+		c, ok := found[id]
+		return c, ok
+	})
+}
+
+// orderComponents - розкладає знайдені компоненти в порядку ids
+func orderComponents(ids []int, lookup func(id int) (models.Component, bool)) ([]models.Component, error) {
+	components := make([]models.Component, 0, len(ids))
+	for _, id := range ids {
+		c, ok := lookup(id)
+		if !ok {
+			return nil, fmt.Errorf("%w (id %d)", ErrComponentNotFound, id)
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// Create додає новий компонент і повертає його ID
+func (r *ComponentRepo) Create(ctx context.Context, c models.Component) (int, error) {
+	query := `INSERT INTO components (name, category, price, image_url, specs)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query, c.Name, c.Category, c.Price, c.ImageURL, []byte(c.Specs)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("помилка створення компонента: %w", err)
+	}
+
+	return id, nil
+}
+
+// Update повністю перезаписує дані компонента (видалені компоненти не оновлюються)
+func (r *ComponentRepo) Update(ctx context.Context, c models.Component) error {
+	query := `UPDATE components SET name = $1, category = $2, price = $3, image_url = $4, specs = $5
+		WHERE id = $6 AND deleted_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, c.Name, c.Category, c.Price, c.ImageURL, []byte(c.Specs), c.ID)
+	if err != nil {
+		return fmt.Errorf("помилка оновлення компонента: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w (id %d)", ErrComponentNotFound, c.ID)
+	}
+
+	return nil
+}
+
+// Delete - м'яке видалення: ставимо deleted_at, рядок лишається для історії замовлень
+func (r *ComponentRepo) Delete(ctx context.Context, id int) error {
+	query := `UPDATE components SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("помилка видалення компонента: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w (id %d)", ErrComponentNotFound, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// HealthPostgres - перевірки доступності БД через основний пул з'єднань
+type HealthPostgres struct {
+	db *sql.DB
+}
+
+func NewHealthPostgres(db *sql.DB) *HealthPostgres {
+	return &HealthPostgres{db: db}
+}
+
+func (r *HealthPostgres) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// SchemaVersion - таблицю schema_migrations веде golang-migrate (один рядок)
+func (r *HealthPostgres) SchemaVersion(ctx context.Context) (int, bool, error) {
+	var version int
+	var dirty bool
+	err := r.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("помилка читання версії схеми: %w", err)
+	}
+	return version, dirty, nil
+}
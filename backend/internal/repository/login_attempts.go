@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// LoginAttemptsPostgres - лічильники в Postgres (спільні для всіх інстансів сервера)
+type LoginAttemptsPostgres struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptsPostgres(db *sql.DB) *LoginAttemptsPostgres {
+	return &LoginAttemptsPostgres{db: db}
+}
+
+func (r *LoginAttemptsPostgres) GetLoginAttempt(ctx context.Context, key string) (models.LoginAttempt, error) {
+	var a models.LoginAttempt
+	var lockedUntil sql.NullTime
+
+	query := "SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key=$1"
+	err := r.db.QueryRowContext(ctx, query, key).Scan(&a.Failures, &a.LastFailureAt, &lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return a, nil
+		}
+		return a, fmt.Errorf("помилка читання спроб входу: %w", err)
+	}
+	a.LockedUntil = lockedUntil.Time
+
+	return a, nil
+}
+
+// RecordLoginFailure - +1 невдала спроба; якщо остання була давніше resetAfter, лічильник починається заново
+func (r *LoginAttemptsPostgres) RecordLoginFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error) {
+	query := `
+		INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING failures`
+
+	var failures int
+	if err := r.db.QueryRowContext(ctx, query, key, resetAfter.Seconds()).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("помилка запису спроби входу: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *LoginAttemptsPostgres) LockLogin(ctx context.Context, key string, until time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE login_attempts SET locked_until=$1 WHERE key=$2", until, key); err != nil {
+		return fmt.Errorf("помилка блокування входу: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptsPostgres) ResetLoginAttempts(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE key=$1", key); err != nil {
+		return fmt.Errorf("помилка скидання спроб входу: %w", err)
+	}
+	return nil
+}
+
+// LoginAttemptsMemory - лічильники в пам'яті процесу (один інстанс, розробка).
+// Після перезапуску сервера лічильники обнуляються.
+type LoginAttemptsMemory struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+// memorySweepSize - при такій кількості ключів прибираємо застарілі записи
+const memorySweepSize = 10000
+
+func NewLoginAttemptsMemory() *LoginAttemptsMemory {
+	return &LoginAttemptsMemory{attempts: make(map[string]models.LoginAttempt)}
+}
+
+func (m *LoginAttemptsMemory) GetLoginAttempt(ctx context.Context, key string) (models.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.attempts[key], nil
+}
+
+func (m *LoginAttemptsMemory) RecordLoginFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if len(m.attempts) >= memorySweepSize {
+		for k, a := range m.attempts {
+			if now.Sub(a.LastFailureAt) > resetAfter && now.After(a.LockedUntil) {
+				delete(m.attempts, k)
+			}
+		}
+	}
+
+	a := m.attempts[key]
+	if now.Sub(a.LastFailureAt) > resetAfter {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	m.attempts[key] = a
+
+	return a.Failures, nil
+}
+
+func (m *LoginAttemptsMemory) LockLogin(ctx context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := m.attempts[key]
+	a.LockedUntil = until
+	m.attempts[key] = a
+	return nil
+}
+
+func (m *LoginAttemptsMemory) ResetLoginAttempts(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.attempts, key)
+	return nil
+}
@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/apperror"
 	"pc-configurator/internal/models"
-	"strings"
 )
 
 type OrderRepo struct {
@@ -17,11 +20,28 @@ func NewOrderRepo(db *sql.DB) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
+// ErrOrderUnknownComponent - у замовленні є id, якого немає в каталозі
+var ErrOrderUnknownComponent = &apperror.Error{
+	Kind:    apperror.ErrValidation,
+	Code:    "unknown_component",
+	Message: "замовлення містить неіснуючий компонент",
+	Field:   "component_ids",
+}
+
+// ErrOrderNotFound - замовлення з таким id не існує
+var ErrOrderNotFound = apperror.NotFound("order_not_found", "замовлення не знайдено")
+
+// CreateOrder - замовлення і його склад (order_items) записуються однією транзакцією.
+// Склад дублюється в orders.component_ids: його досі читають інстанси попередньої версії
+// (див. міграцію 000014).
 func (r *OrderRepo) CreateOrder(order models.OrderRequest) (int, error) {
 	var id int
 
-	// Перетворюємо масив ID ([1, 2]) у JSON рядок ("[1, 2]") для бази
-	componentsJson, err := json.Marshal(order.ComponentIDs)
+	componentIDs := order.ComponentIDs
+	if componentIDs == nil {
+		componentIDs = []int{}
+	}
+	componentsJson, err := json.Marshal(componentIDs)
 	if err != nil {
 		return 0, err
 	}
@@ -32,13 +52,34 @@ func (r *OrderRepo) CreateOrder(order models.OrderRequest) (int, error) {
 		userID = order.UserID
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Зовнішнього ключа на components у order_items немає - перевіряємо id тут
+	ids := pq.Array(toInt64s(componentIDs))
+	var unknown bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM unnest($1::int[]) AS t(id)
+			WHERE NOT EXISTS (SELECT 1 FROM components c WHERE c.id = t.id)
+		)`, ids).Scan(&unknown)
+	if err != nil {
+		return 0, fmt.Errorf("помилка перевірки складу замовлення: %w", err)
+	}
+	if unknown {
+		return 0, ErrOrderUnknownComponent
+	}
+
 	// Вставляємо status разом із замовленням (за замовчуванням 'pending')
 	query := `
 		INSERT INTO orders (customer_name, phone, delivery_address, payment_method, total_price, component_ids, user_id, status)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`
 
-	err = r.db.QueryRow(query,
+	err = tx.QueryRow(query,
 		order.CustomerName,
 		order.Phone,
 		order.DeliveryAddress,
@@ -53,29 +94,46 @@ func (r *OrderRepo) CreateOrder(order models.OrderRequest) (int, error) {
 		return 0, fmt.Errorf("помилка запису замовлення: %w", err)
 	}
 
+	// Увесь склад одним запитом; позиція зберігає порядок компонентів у збірці
+	itemsQuery := `
+		INSERT INTO order_items (order_id, position, component_id)
+		SELECT $1, t.position, t.component_id
+		FROM unnest($2::int[]) WITH ORDINALITY AS t(component_id, position)`
+
+	if _, err := tx.Exec(itemsQuery, id, ids); err != nil {
+		return 0, fmt.Errorf("помилка запису складу замовлення: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("помилка запису замовлення: %w", err)
+	}
+
 	return id, nil
 }
 
-// GetUserOrders - Отримання замовлень користувача за ID (для профілю)
-func (r *OrderRepo) GetUserOrders(userID int) ([]map[string]interface{}, error) {
+// GetUserOrders - Отримання замовлень користувача за ID (для профілю).
+// Склад і назви компонентів збираються тим самим запитом, без окремого запиту на замовлення.
+func (r *OrderRepo) GetUserOrders(ctx context.Context, userID int) ([]map[string]interface{}, error) {
 	// Запит отримує замовлення користувача, впорядковані за датою (найновіші спочатку)
 	query := `
-		SELECT id, customer_name, total_price, payment_method, created_at, COALESCE(status, 'pending') as status, component_ids
-		FROM orders
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT o.id, o.customer_name, o.total_price, o.payment_method, o.created_at, COALESCE(o.status, 'pending') as status,
+		       COALESCE(array_agg(oi.component_id ORDER BY oi.position) FILTER (WHERE oi.component_id IS NOT NULL), '{}'),
+		       COALESCE(array_agg(c.name ORDER BY oi.position) FILTER (WHERE c.name IS NOT NULL), '{}')
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id
+		LEFT JOIN components c ON c.id = oi.component_id
+		WHERE o.user_id = $1
+		GROUP BY o.id
+		ORDER BY o.created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
-		// Якщо помилка (напр. колона не існує), повертаємо порожній список замість помилки
-		// Це тимчасово, поки БД не буде мігрована
-		log.Printf("Warning: GetUserOrders error: %v. Returning empty list.", err)
-		return []map[string]interface{}{}, nil
+		return nil, fmt.Errorf("помилка отримання замовлень: %w", err)
 	}
 	defer rows.Close()
 
-	var orders []map[string]interface{}
+	orders := []map[string]interface{}{}
 
 	for rows.Next() {
 		var id int
@@ -84,55 +142,12 @@ func (r *OrderRepo) GetUserOrders(userID int) ([]map[string]interface{}, error)
 		var paymentMethod string
 		var createdAt string
 		var status string
-		var componentsJSON []byte
+		var compIDs pq.Int64Array
+		var compNames pq.StringArray
 
-		err = rows.Scan(&id, &customerName, &totalPrice, &paymentMethod, &createdAt, &status, &componentsJSON)
+		err = rows.Scan(&id, &customerName, &totalPrice, &paymentMethod, &createdAt, &status, &compIDs, &compNames)
 		if err != nil {
-			log.Printf("Error scanning order row: %v", err)
-			continue
-		}
-
-		// Парсимо component_ids (JSON) щоб порахувати кількість компонентів
-		var compIDs []int
-		if len(componentsJSON) > 0 {
-			if err := json.Unmarshal(componentsJSON, &compIDs); err != nil {
-				log.Printf("Error unmarshalling component_ids for order %d: %v", id, err)
-			}
-		}
-
-		// Отримуємо назви компонентів з таблиці components
-		var compNames []string
-		if len(compIDs) > 0 {
-			// Підготуємо плейсхолдери і аргументи
-			placeholders := make([]string, len(compIDs))
-			args := make([]interface{}, len(compIDs))
-			for i, cid := range compIDs {
-				placeholders[i] = fmt.Sprintf("$%d", i+1)
-				args[i] = cid
-			}
-			q := fmt.Sprintf("SELECT id, name FROM components WHERE id IN (%s)", strings.Join(placeholders, ","))
-			rows2, err := r.db.Query(q, args...)
-			if err != nil {
-				log.Printf("Error querying component names for order %d: %v", id, err)
-			} else {
-				defer rows2.Close()
-				var cid int
-				var cname string
-				nameMap := make(map[int]string)
-				for rows2.Next() {
-					if err := rows2.Scan(&cid, &cname); err != nil {
-						log.Printf("Error scanning component row: %v", err)
-						continue
-					}
-					nameMap[cid] = cname
-				}
-				// Зберемо імена в порядку id, якщо можливий
-				for _, cid := range compIDs {
-					if n, ok := nameMap[cid]; ok {
-						compNames = append(compNames, n)
-					}
-				}
-			}
+			return nil, err
 		}
 
 		orders = append(orders, map[string]interface{}{
@@ -143,15 +158,113 @@ func (r *OrderRepo) GetUserOrders(userID int) ([]map[string]interface{}, error)
 			"created_at":      createdAt,
 			"status":          status,
 			"component_count": len(compIDs),
-			"component_ids":   compIDs,
-			"component_names": compNames,
+			"component_ids":   fromInt64s(compIDs),
+			"component_names": []string(compNames),
 		})
 	}
 
-	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating orders: %v", err)
-		return []map[string]interface{}{}, nil
+	return orders, rows.Err()
+}
+
+// GetUserOrdersFull - замовлення користувача з контактами та адресою доставки
+func (r *OrderRepo) GetUserOrdersFull(ctx context.Context, userID int) ([]models.Order, error) {
+	query := `
+		SELECT o.id, o.customer_name, o.phone, o.delivery_address, o.payment_method, o.total_price,
+		       COALESCE(array_agg(oi.component_id ORDER BY oi.position) FILTER (WHERE oi.component_id IS NOT NULL), '{}'),
+		       COALESCE(o.status, 'pending'), o.created_at
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id
+		WHERE o.user_id = $1
+		GROUP BY o.id
+		ORDER BY o.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання замовлень: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		var o models.Order
+		var compIDs pq.Int64Array
+		err := rows.Scan(&o.ID, &o.CustomerName, &o.Phone, &o.DeliveryAddress, &o.PaymentMethod, &o.TotalPrice,
+			&compIDs, &o.Status, &o.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		o.ComponentIDs = fromInt64s(compIDs)
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetAllOrders - замовлення всіх користувачів (найновіші спочатку), за потреби - лише з одним статусом
+func (r *OrderRepo) GetAllOrders(ctx context.Context, status string) ([]models.Order, error) {
+	query := `
+		SELECT o.id, o.user_id, o.customer_name, o.phone, o.delivery_address, o.payment_method, o.total_price,
+		       COALESCE(array_agg(oi.component_id ORDER BY oi.position) FILTER (WHERE oi.component_id IS NOT NULL), '{}'),
+		       COALESCE(o.status, 'pending'), o.created_at
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id
+		WHERE $1 = '' OR COALESCE(o.status, 'pending') = $1
+		GROUP BY o.id
+		ORDER BY o.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання замовлень: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		var o models.Order
+		var userID sql.NullInt64
+		var compIDs pq.Int64Array
+		err := rows.Scan(&o.ID, &userID, &o.CustomerName, &o.Phone, &o.DeliveryAddress, &o.PaymentMethod, &o.TotalPrice,
+			&compIDs, &o.Status, &o.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			o.UserID = &id
+		}
+		o.ComponentIDs = fromInt64s(compIDs)
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
+// UpdateOrderStatus - новий статус замовлення
+func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, orderID int, status string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE orders SET status = $2 WHERE id = $1", orderID, status)
+	if err != nil {
+		return fmt.Errorf("помилка оновлення статусу замовлення: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrOrderNotFound
 	}
+	return nil
+}
+
+// anonymizedCustomerName - ім'я покупця у знеособлених замовленнях
+const anonymizedCustomerName = "Видалений користувач"
 
-	return orders, nil
+// anonymizeUserOrders - відв'язує замовлення від користувача і стирає його контакти.
+// Суми, склад, спосіб оплати, статус і дата лишаються для бухгалтерського обліку.
+// Викликається з AuthPostgres.DeleteUser у тій самій транзакції, що й видалення.
+func anonymizeUserOrders(tx *sql.Tx, userID int) error {
+	query := `
+		UPDATE orders
+		SET user_id = NULL, customer_name = $2, phone = '', delivery_address = ''
+		WHERE user_id = $1`
+
+	if _, err := tx.Exec(query, userID, anonymizedCustomerName); err != nil {
+		return fmt.Errorf("помилка знеособлення замовлень: %w", err)
+	}
+	return nil
 }
@@ -6,27 +6,22 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
-)
 
-const (
-	_defaultMaxOpenConns = 25
-	_defaultMaxIdleConns = 25
-	_defaultConnLifetime = 5 * time.Minute
-	_defaultConnAttempts = 3
-	_defaultConnTimeout  = time.Second
+	"pc-configurator/config"
 )
 
-func NewPostgresDB(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", dsn)
+// NewPostgresDB - відкриває пул з'єднань за налаштуваннями cfg і чекає, доки БД відповість
+func NewPostgresDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("помилка відкриття драйвера postgres: %w", err)
 	}
 
-	db.SetMaxOpenConns(_defaultMaxOpenConns)
-	db.SetMaxIdleConns(_defaultMaxIdleConns)
-	db.SetConnMaxLifetime(_defaultConnLifetime)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
-	if err := retryPing(db, _defaultConnAttempts, _defaultConnTimeout); err != nil {
+	if err := retryPing(db, cfg.ConnAttempts, cfg.ConnRetryDelay); err != nil {
 		return nil, fmt.Errorf("не вдалося підключитися до БД: %w", err)
 	}
 
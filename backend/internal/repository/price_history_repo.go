@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// PriceHistoryRepo - читання таблиці price_history (записи створює тригер у БД)
+type PriceHistoryRepo struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryRepo(db *sql.DB) *PriceHistoryRepo {
+	return &PriceHistoryRepo{db: db}
+}
+
+// GetPriceHistory повертає зміни ціни з моменту since (від старих до нових).
+// Першою йде остання зміна ДО since — це ціна, що діяла на початок періоду.
+func (r *PriceHistoryRepo) GetPriceHistory(ctx context.Context, componentID int, since time.Time) ([]models.PricePoint, error) {
+	query := `
+		(SELECT price, changed_at FROM price_history
+		 WHERE component_id = $1 AND changed_at < $2
+		 ORDER BY changed_at DESC LIMIT 1)
+		UNION ALL
+		(SELECT price, changed_at FROM price_history
+		 WHERE component_id = $1 AND changed_at >= $2)
+		ORDER BY changed_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, componentID, since)
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання історії цін: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.PricePoint{}
+	for rows.Next() {
+		var p models.PricePoint
+		if err := rows.Scan(&p.Price, &p.ChangedAt); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// RateLimiterMemory - відра токенів у пам'яті процесу.
+// Кожен інстанс рахує окремо, тож за балансувальником ліміт фактично множиться на кількість інстансів.
+type RateLimiterMemory struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// rateLimiterSweepInterval - повні відра прибираємо не частіше за цей інтервал:
+// прохід по всій мапі під м'ютексом на кожен запит гальмував би всі запити разом
+const rateLimiterSweepInterval = time.Minute
+
+// tokenBucket - стан відра на момент updatedAt
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time // Коли відро наповниться - після цього запис можна видалити
+}
+
+func NewRateLimiterMemory() *RateLimiterMemory {
+	return &RateLimiterMemory{buckets: make(map[string]*tokenBucket)}
+}
+
+func (m *RateLimiterMemory) Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	return m.take(key, limit, time.Now()), nil
+}
+
+func (m *RateLimiterMemory) take(key string, limit models.RateLimit, now time.Time) models.RateLimitResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.buckets) >= memorySweepSize && now.Sub(m.lastSweep) >= rateLimiterSweepInterval {
+		m.lastSweep = now
+		for k, b := range m.buckets {
+			if now.After(b.fullAt) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+
+	capacity := float64(limit.Requests)
+	perToken := limit.Per / time.Duration(limit.Requests)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	// Поповнюємо за час з останнього запиту, але не більше місткості
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updatedAt))/float64(perToken))
+	b.updatedAt = now
+
+	res := models.RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	res.Remaining = int(b.tokens)
+	res.ResetAfter = time.Duration((capacity - b.tokens) * float64(perToken))
+	b.fullAt = now.Add(res.ResetAfter)
+
+	return res
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+func TestRateLimiterMemoryTake(t *testing.T) {
+	m := NewRateLimiterMemory()
+	limit := models.RateLimit{Requests: 3, Per: 3 * time.Second} // Токен щосекунди
+	start := time.Unix(1_700_000_000, 0)
+
+	// Повне відро - три запити поспіль
+	for i := 2; i >= 0; i-- {
+		res := m.take("ip:1", limit, start)
+		if !res.Allowed || res.Remaining != i {
+			t.Fatalf("burst: %+v, want allowed with %d remaining", res, i)
+		}
+	}
+	if res := m.take("ip:1", limit, start); res.ResetAfter != 3*time.Second {
+		t.Errorf("ResetAfter = %v, want 3s", res.ResetAfter)
+	}
+
+	res := m.take("ip:1", limit, start.Add(400*time.Millisecond))
+	if res.Allowed || res.RetryAfter != 600*time.Millisecond {
+		t.Errorf("empty bucket: %+v, want denied with RetryAfter 600ms", res)
+	}
+
+	// Інші ключі мають свої відра
+	if res := m.take("ip:2", limit, start); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("other key: %+v", res)
+	}
+
+	// За секунду з'являється один токен
+	if res := m.take("ip:1", limit, start.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after refill: %+v", res)
+	}
+
+	// Відро не наповнюється понад місткість
+	if res := m.take("ip:1", limit, start.Add(time.Hour)); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("after a long pause: %+v", res)
+	}
+}
+
+func TestRateLimiterMemorySweep(t *testing.T) {
+	m := NewRateLimiterMemory()
+	limit := models.RateLimit{Requests: 1, Per: time.Second}
+	start := time.Unix(1_700_000_000, 0)
+
+	fill := func(prefix string, at time.Time) {
+		for i := range memorySweepSize {
+			m.take(fmt.Sprintf("%s:%d", prefix, i), limit, at)
+		}
+	}
+
+	fill("a", start)
+	if len(m.buckets) != memorySweepSize {
+		t.Fatalf("%d buckets, want %d", len(m.buckets), memorySweepSize)
+	}
+
+	// Відра "a" вже повні - їх прибирає перший запит після порогу
+	now := start.Add(time.Hour)
+	m.take("b", limit, now)
+	if len(m.buckets) != 1 {
+		t.Fatalf("after sweep: %d buckets, want 1", len(m.buckets))
+	}
+
+	// Наступний прохід - не раніше, ніж через інтервал
+	fill("c", now)
+	now = now.Add(rateLimiterSweepInterval / 2)
+	m.take("d", limit, now)
+	if len(m.buckets) != memorySweepSize+2 {
+		t.Fatalf("sweep ran before the interval: %d buckets", len(m.buckets))
+	}
+
+	now = now.Add(rateLimiterSweepInterval)
+	m.take("e", limit, now)
+	if len(m.buckets) != 1 {
+		t.Fatalf("after the interval: %d buckets, want 1", len(m.buckets))
+	}
+}
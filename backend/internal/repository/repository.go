@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"pc-configurator/internal/models"
 )
 
@@ -13,6 +15,57 @@ type Authorization interface {
 	UpdatePassword(userID int, newPasswordHash string) error
 	// UpdateProfile - оновлення імені та email користувача
 	UpdateProfile(userID int, name, email string) error
+	// UpdateRole - зміна ролі користувача (customer, manager, admin)
+	UpdateRole(userID int, role string) error
+
+	// Refresh-токени (зберігається лише хеш)
+	// authTime - час входу, що відкрив сесію (зберігається при ротації токенів)
+	CreateRefreshToken(userID int, tokenHash string, expiresAt, authTime time.Time) error
+	GetRefreshToken(tokenHash string) (models.RefreshToken, error)
+	RevokeRefreshToken(tokenHash string) (bool, error)
+	RevokeAllRefreshTokens(userID int) error
+
+	// Одноразові токени з листів (підтвердження email, скидання пароля)
+	CreateUserToken(userID int, purpose, tokenHash string, expiresAt time.Time) error
+	ConsumeUserToken(purpose, tokenHash string) (int, error)
+	MarkEmailVerified(userID int) error
+
+	// Зовнішні облікові записи (вхід через OpenID Connect)
+	GetUserByIdentity(provider, subject string) (models.User, error)
+	CreateIdentity(identity models.UserIdentity) error
+	// CreateUserWithIdentity - новий користувач і його зовнішній запис в одній транзакції
+	CreateUserWithIdentity(user models.User, identity models.UserIdentity) (int, error)
+	GetUserIdentities(userID int) ([]models.UserIdentity, error)
+
+	// Двофакторна автентифікація (TOTP і коди відновлення)
+	GetTwoFactor(userID int) (models.TwoFactor, error)
+	// SetPendingTOTPSecret - новий секрет, що почне діяти лише після EnableTwoFactor
+	SetPendingTOTPSecret(userID int, secret string) error
+	// EnableTwoFactor - вмикає 2FA і замінює коди відновлення (в одній транзакції)
+	EnableTwoFactor(userID int, recoveryCodeHashes []string) error
+	DisableTwoFactor(userID int) error
+	ReplaceRecoveryCodes(userID int, recoveryCodeHashes []string) error
+	// UseTOTPStep - false, якщо код цього або пізнішого інтервалу вже використано
+	UseTOTPStep(userID int, step int64) (bool, error)
+	// ConsumeRecoveryCode - false, якщо коду немає або його вже використано
+	ConsumeRecoveryCode(userID int, codeHash string) (bool, error)
+
+	// DeleteUser - однією транзакцією знеособлює замовлення користувача (вони лишаються
+	// для обліку) і видаляє його разом з сесіями, токенами, відстеженнями та прив'язками
+	DeleteUser(userID int) error
+}
+
+// Orders - замовлення користувачів
+type Orders interface {
+	CreateOrder(order models.OrderRequest) (int, error)
+	GetUserOrders(ctx context.Context, userID int) ([]map[string]interface{}, error)
+	// GetUserOrdersFull - усі замовлення з даними покупця (для експорту)
+	GetUserOrdersFull(ctx context.Context, userID int) ([]models.Order, error)
+
+	// GetAllOrders - замовлення всіх користувачів для адміністрування; status "" - будь-який
+	GetAllOrders(ctx context.Context, status string) ([]models.Order, error)
+	// UpdateOrderStatus - зміна статусу замовлення менеджером
+	UpdateOrderStatus(ctx context.Context, orderID int, status string) error
 }
 
 // ComponentRepository - інтерфейс для роботи з товарами
@@ -23,4 +76,61 @@ type ComponentRepository interface {
 	GetAll(ctx context.Context, category string, minPrice, maxPrice float64, search string, sort string) ([]models.Component, error)
 
 	GetByID(ctx context.Context, id int) (*models.Component, error)
+	// GetByIDs - компоненти в порядку ids (повтори зберігаються) одним запитом;
+	// якщо якогось id немає - ErrComponentNotFound
+	GetByIDs(ctx context.Context, ids []int) ([]models.Component, error)
+
+	// Керування каталогом (адмінка)
+	Create(ctx context.Context, c models.Component) (int, error)
+	Update(ctx context.Context, c models.Component) error
+	Delete(ctx context.Context, id int) error
+}
+
+// CatalogImport - масовий імпорт прайс-листів постачальника (upsert за supplier_sku)
+type CatalogImport interface {
+	ImportComponents(ctx context.Context, items []models.ImportItem, dryRun bool) (models.ImportReport, error)
+}
+
+// PriceHistory - історія змін цін компонентів
+type PriceHistory interface {
+	GetPriceHistory(ctx context.Context, componentID int, since time.Time) ([]models.PricePoint, error)
+}
+
+// Watchlist - відстеження цін і внутрішні сповіщення
+type Watchlist interface {
+	CreateWatch(ctx context.Context, w models.Watch) (int, error)
+	GetUserWatches(ctx context.Context, userID int) ([]models.Watch, error)
+	GetAllWatches(ctx context.Context) ([]models.Watch, error)
+	DeleteWatch(ctx context.Context, userID, watchID int) error
+	SetLastNotifiedPrice(ctx context.Context, watchID int, price *float64) error
+	// NotifyPriceDrop - запам'ятовує price як last_notified_price і створює сповіщення однією
+	// транзакцією. Оновлення умовне (last_notified_price досі дорівнює prev): false означає,
+	// що відстеження вже обробив інший прохід, і сповіщення не створюється.
+	NotifyPriceDrop(ctx context.Context, watchID int, prev *float64, price float64, n models.Notification) (bool, error)
+
+	CreateNotification(ctx context.Context, n models.Notification) (int64, error)
+	// limit <= 0 - усі сповіщення
+	GetUserNotifications(ctx context.Context, userID int, limit int) ([]models.Notification, error)
+	MarkNotificationsRead(ctx context.Context, userID int, id int64) error
+}
+
+// LoginAttempts - лічильники невдалих входів (Postgres або пам'ять процесу)
+type LoginAttempts interface {
+	GetLoginAttempt(ctx context.Context, key string) (models.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+// RateLimiter - відра токенів для обмеження частоти запитів.
+// Take забирає один токен з відра key, якщо він є.
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error)
+}
+
+// Health - перевірки стану БД для /readyz
+type Health interface {
+	Ping(ctx context.Context) error
+	// SchemaVersion - версія застосованих міграцій і чи не зупинилась остання посередині
+	SchemaVersion(ctx context.Context) (version int, dirty bool, err error)
 }
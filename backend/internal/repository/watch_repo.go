@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/models"
+)
+
+// WatchRepo - відстеження цін і внутрішні сповіщення користувачів
+type WatchRepo struct {
+	db *sql.DB
+}
+
+func NewWatchRepo(db *sql.DB) *WatchRepo {
+	return &WatchRepo{db: db}
+}
+
+// watchSelect - поточна ціна рахується як сума цін усіх компонентів відстеження.
+// Якщо якийсь компонент видалено з каталогу, current_price = NULL.
+const watchSelect = `
+	SELECT w.id, w.user_id, u.email, w.kind, w.name, w.component_ids, w.target_price,
+	       w.last_notified_price, w.created_at,
+	       CASE WHEN p.found = cardinality(w.component_ids) THEN p.total END AS current_price
+	FROM watches w
+	JOIN users u ON u.id = w.user_id
+	CROSS JOIN LATERAL (
+		SELECT SUM(c.price) AS total, COUNT(c.id) AS found
+		FROM unnest(w.component_ids) AS wc(id)
+		LEFT JOIN components c ON c.id = wc.id AND c.deleted_at IS NULL
+	) p`
+
+// CreateWatch - додає відстеження і повертає його ID
+func (r *WatchRepo) CreateWatch(ctx context.Context, w models.Watch) (int, error) {
+	query := `INSERT INTO watches (user_id, kind, name, component_ids, target_price)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query, w.UserID, w.Kind, w.Name, pq.Array(toInt64s(w.ComponentIDs)), w.TargetPrice).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("помилка створення відстеження: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUserWatches - усі відстеження користувача з поточними цінами
+func (r *WatchRepo) GetUserWatches(ctx context.Context, userID int) ([]models.Watch, error) {
+	return r.queryWatches(ctx, watchSelect+` WHERE w.user_id = $1 ORDER BY w.created_at DESC`, userID)
+}
+
+// GetAllWatches - усі відстеження (для фонової перевірки цін)
+func (r *WatchRepo) GetAllWatches(ctx context.Context) ([]models.Watch, error) {
+	return r.queryWatches(ctx, watchSelect+` ORDER BY w.id`)
+}
+
+// ErrWatchNotFound - відстеження немає або воно належить іншому користувачу
+var ErrWatchNotFound = apperror.NotFound("watch_not_found", "відстеження не знайдено")
+
+// DeleteWatch - видаляє відстеження, якщо воно належить користувачу
+func (r *WatchRepo) DeleteWatch(ctx context.Context, userID, watchID int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM watches WHERE id = $1 AND user_id = $2`, watchID, userID)
+	if err != nil {
+		return fmt.Errorf("помилка видалення відстеження: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w (id %d)", ErrWatchNotFound, watchID)
+	}
+	return nil
+}
+
+// SetLastNotifiedPrice - запам'ятовує ціну, про яку повідомили (nil - скинути)
+func (r *WatchRepo) SetLastNotifiedPrice(ctx context.Context, watchID int, price *float64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE watches SET last_notified_price = $1 WHERE id = $2`, price, watchID)
+	if err != nil {
+		return fmt.Errorf("помилка оновлення відстеження: %w", err)
+	}
+	return nil
+}
+
+// NotifyPriceDrop - нова ціна сповіщення і саме сповіщення записуються разом:
+// збій між ними не призведе до повторного сповіщення на наступному проході
+func (r *WatchRepo) NotifyPriceDrop(ctx context.Context, watchID int, prev *float64, price float64, n models.Notification) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE watches SET last_notified_price = $1
+		WHERE id = $2 AND last_notified_price IS NOT DISTINCT FROM $3`, price, watchID, prev)
+	if err != nil {
+		return false, fmt.Errorf("помилка оновлення відстеження: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return false, nil
+	}
+
+	if _, err := insertNotification(ctx, tx, n); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("помилка створення сповіщення: %w", err)
+	}
+	return true, nil
+}
+
+// CreateNotification - записує внутрішнє сповіщення
+func (r *WatchRepo) CreateNotification(ctx context.Context, n models.Notification) (int64, error) {
+	return insertNotification(ctx, r.db, n)
+}
+
+// queryRower - *sql.DB або *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func insertNotification(ctx context.Context, db queryRower, n models.Notification) (int64, error) {
+	data := []byte(n.Data)
+	if len(data) == 0 {
+		data = []byte(`{}`)
+	}
+
+	query := `INSERT INTO notifications (user_id, type, title, message, data)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id int64
+	if err := db.QueryRowContext(ctx, query, n.UserID, n.Type, n.Title, n.Message, data).Scan(&id); err != nil {
+		return 0, fmt.Errorf("помилка створення сповіщення: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUserNotifications - останні limit сповіщень користувача (найновіші спочатку), limit <= 0 - усі
+func (r *WatchRepo) GetUserNotifications(ctx context.Context, userID int, limit int) ([]models.Notification, error) {
+	query := `SELECT id, user_id, type, title, message, data, read_at, created_at
+		FROM notifications WHERE user_id = $1
+		ORDER BY created_at DESC LIMIT $2`
+
+	// LIMIT NULL у Postgres означає "без обмеження"
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limitArg)
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання сповіщень: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message, &n.Data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkNotificationsRead - позначає сповіщення прочитаними (id == 0 - усі)
+func (r *WatchRepo) MarkNotificationsRead(ctx context.Context, userID int, id int64) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	args := []interface{}{userID}
+	if id != 0 {
+		query += ` AND id = $2`
+		args = append(args, id)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("помилка оновлення сповіщень: %w", err)
+	}
+	return nil
+}
+
+func (r *WatchRepo) queryWatches(ctx context.Context, query string, args ...interface{}) ([]models.Watch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("помилка отримання відстежень: %w", err)
+	}
+	defer rows.Close()
+
+	watches := []models.Watch{}
+	for rows.Next() {
+		var w models.Watch
+		var ids pq.Int64Array
+		var lastNotified, current sql.NullFloat64
+
+		err := rows.Scan(&w.ID, &w.UserID, &w.UserEmail, &w.Kind, &w.Name, &ids, &w.TargetPrice,
+			&lastNotified, &w.CreatedAt, &current)
+		if err != nil {
+			return nil, err
+		}
+
+		w.ComponentIDs = fromInt64s(ids)
+		if lastNotified.Valid {
+			w.LastNotifiedPrice = &lastNotified.Float64
+		}
+		if current.Valid {
+			w.CurrentPrice = &current.Float64
+		}
+
+		watches = append(watches, w)
+	}
+
+	return watches, rows.Err()
+}
+
+func toInt64s(ids []int) []int64 {
+	out := make([]int64, len(ids))
+	for i, id := range ids {
+		out[i] = int64(id)
+	}
+	return out
+}
+
+func fromInt64s(ids []int64) []int {
+	out := make([]int, len(ids))
+	for i, id := range ids {
+		out[i] = int(id)
+	}
+	return out
+}
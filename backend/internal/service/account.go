@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+)
+
+// AccountService - персональні дані користувача: експорт і видалення акаунта
+type AccountService struct {
+	auth    *AuthService
+	repo    repository.Authorization
+	orders  repository.Orders
+	watches repository.Watchlist
+}
+
+func NewAccountService(auth *AuthService, repo repository.Authorization, orders repository.Orders, watches repository.Watchlist) *AccountService {
+	return &AccountService{auth: auth, repo: repo, orders: orders, watches: watches}
+}
+
+// ExportData - усе, що ми зберігаємо про користувача, одним документом
+func (s *AccountService) ExportData(ctx context.Context, userID int) (models.AccountExport, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return models.AccountExport{}, err
+	}
+
+	identities, err := s.repo.GetUserIdentities(userID)
+	if err != nil {
+		return models.AccountExport{}, err
+	}
+	orders, err := s.orders.GetUserOrdersFull(ctx, userID)
+	if err != nil {
+		return models.AccountExport{}, err
+	}
+	watches, err := s.watches.GetUserWatches(ctx, userID)
+	if err != nil {
+		return models.AccountExport{}, err
+	}
+	notifications, err := s.watches.GetUserNotifications(ctx, userID, 0)
+	if err != nil {
+		return models.AccountExport{}, err
+	}
+
+	return models.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: models.AccountProfile{
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Role:            user.Role,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+		},
+		Identities:    identities,
+		Orders:        orders,
+		Watches:       watches,
+		Notifications: notifications,
+	}, nil
+}
+
+// reauthMaxAge - наскільки давнім може бути вхід, щоб видалити акаунт без пароля
+const reauthMaxAge = 10 * time.Minute
+
+// ErrReauthRequired - для небезпечної дії потрібен пароль або свіжий вхід
+var ErrReauthRequired = apperror.Forbidden("reauth_required", "підтвердіть дію паролем або увійдіть знову")
+
+// DeleteAccount - видаляє акаунт. Підтвердження - пароль або вхід не давніший за reauthMaxAge
+// (authTime з access-токена): так акаунт може видалити і користувач, що входить лише через OIDC.
+// Замовлення знеособлюються (вони лишаються для обліку) в одній транзакції з видаленням.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID int, password string, authTime time.Time) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+			return ErrWrongPassword
+		}
+	} else if authTime.IsZero() || time.Since(authTime) > reauthMaxAge {
+		return ErrReauthRequired
+	}
+
+	if err := s.repo.DeleteUser(userID); err != nil {
+		return err
+	}
+
+	// Лічильник невдалих входів містить email - прибираємо і його
+	if err := s.auth.guard.Success(ctx, user.Email); err != nil {
+		logging.FromContext(ctx).Warn("login guard cleanup failed", "user_id", userID, "error", err)
+	}
+
+	return nil
+}
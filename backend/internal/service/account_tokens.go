@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/validation"
+)
+
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// ErrInvalidAccountToken - токен з листа не існує, протух або вже використаний
+var ErrInvalidAccountToken = apperror.Validation("invalid_account_token", "посилання недійсне або протухло")
+
+// SendVerificationEmail - надсилає лист з посиланням для підтвердження email
+func (s *AuthService) SendVerificationEmail(ctx context.Context, userID int) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	token, err := s.createAccountToken(user.ID, models.TokenPurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Вітаємо, %s!\n\nПідтвердіть свою адресу, перейшовши за посиланням:\n%s\n\nПосилання дійсне 24 години.",
+		user.Name, link)
+
+	return s.mailer.Send(ctx, user.Email, "Підтвердження email", body)
+}
+
+// VerifyEmail - підтверджує email за токеном з листа
+func (s *AuthService) VerifyEmail(token string) error {
+	userID, err := s.repo.ConsumeUserToken(models.TokenPurposeVerifyEmail, hashToken(token))
+	if errors.Is(err, apperror.ErrNotFound) {
+		return ErrInvalidAccountToken
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.MarkEmailVerified(userID)
+}
+
+// RequestPasswordReset - надсилає лист зі скиданням пароля.
+// Якщо користувача немає, мовчки нічого не робить, щоб не розкривати,
+// які email зареєстровані.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(validation.NormalizeEmail(email))
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.createAccountToken(user.ID, models.TokenPurposeResetPassword, resetPasswordTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Вітаємо, %s!\n\nЩоб встановити новий пароль, перейдіть за посиланням:\n%s\n\n"+
+		"Посилання дійсне 1 годину. Якщо ви не запитували скидання пароля, просто проігноруйте цей лист.",
+		user.Name, link)
+
+	if err := s.mailer.Send(ctx, user.Email, "Скидання пароля", body); err != nil {
+		// Користувачу відповідаємо однаково, а проблему з поштою бачимо в логах
+		logging.FromContext(ctx).Warn("password reset email failed", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// ResetPassword - встановлює новий пароль за токеном з листа і завершує всі сесії
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	if err := validatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.repo.ConsumeUserToken(models.TokenPurposeResetPassword, hashToken(token))
+	if errors.Is(err, apperror.ErrNotFound) {
+		return ErrInvalidAccountToken
+	}
+	if err != nil {
+		return err
+	}
+
+	hash, err := generatePasswordHash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(userID, hash); err != nil {
+		return err
+	}
+
+	// Лист прийшов на цю адресу - отже, email теж підтверджено
+	if err := s.repo.MarkEmailVerified(userID); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeAllRefreshTokens(userID)
+}
+
+func (s *AuthService) createAccountToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreateUserToken(userID, purpose, hashToken(token), time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -1,42 +1,91 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
 	"pc-configurator/internal/models"
 	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
 )
 
-const (
-	// Секретний ключ для підпису токенів.
-	// У реальному проекті це має бути в змінних середовища (os.Getenv)
-	signingKey = "qrkjk#4#%35FSFJlja#4353KSFjH"
-	// Час життя токена (наприклад, 12 годин)
-	tokenTTL = 12 * time.Hour
-)
+// ErrInvalidRefreshToken - токен не існує, протух або вже використаний
+var ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "невалідний refresh-токен")
+
+// Tokens - пара токенів, що отримує клієнт після входу
+type Tokens struct {
+	AccessToken  string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"` // Час життя access-токена (або challenge-токена), секунд
+
+	// Увімкнено 2FA: токенів ще немає, ChallengeToken обмінюється на них разом з кодом
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
 
 // tokenClaims - структура для даних, що зашиті всередині JWT токена
 type tokenClaims struct {
-	UserID int `json:"user_id"`
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"`
+	// AuthTime - коли користувач увійшов (як у OIDC); при оновленні токенів не змінюється
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// AccessClaims - дані з перевіреного access-токена
+type AccessClaims struct {
+	UserID   int
+	Role     string
+	AuthTime time.Time // Нульовий, якщо токен виданий сесії без відомого часу входу
+}
+
 // AuthService відповідає за реєстрацію та логін
 type AuthService struct {
-	repo repository.Authorization // Інтерфейс репозиторія користувачів
+	repo   repository.Authorization // Інтерфейс репозиторія користувачів
+	keys   *KeyRing                 // Ключі підпису JWT (з конфігурації)
+	mailer Mailer                   // Листи підтвердження email та скидання пароля
+	appURL string                   // Адреса фронтенду для посилань у листах
+	guard  *LoginGuard              // Захист від підбору пароля
+
+	accessTTL  time.Duration // Час життя access-токена
+	refreshTTL time.Duration // Час життя refresh-токена
 }
 
 // NewAuthService - конструктор
-func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repository.Authorization, keys *KeyRing, mailer Mailer, appURL string, guard *LoginGuard, accessTTL, refreshTTL time.Duration) *AuthService {
+	return &AuthService{repo: repo, keys: keys, mailer: mailer, appURL: appURL, guard: guard, accessTTL: accessTTL, refreshTTL: refreshTTL}
 }
 
 // CreateUser - реєструє нового користувача
-func (s *AuthService) CreateUser(user models.User) (int, error) {
+func (s *AuthService) CreateUser(ctx context.Context, user models.User) (int, error) {
+	// 0. Валідація: помилки по кожному полю повертаємо разом
+	user.Name = strings.TrimSpace(user.Name)
+	user.Email = validation.NormalizeEmail(user.Email)
+
+	errs := validation.Errors{}
+	errs.Check("name", validation.Name(user.Name))
+	errs.Check("email", validation.Email(user.Email))
+	errs.Check("password", validation.Password(user.Password))
+	if err := errs.Err(); err != nil {
+		return 0, err
+	}
+
+	// UNIQUE у БД чутливий до регістру, а старі адреси можуть бути не нормалізовані
+	if _, err := s.repo.GetUserByEmail(user.Email); err == nil {
+		return 0, repository.ErrEmailTaken
+	}
+
 	// 1. Хешування пароля
 	// Ми ніколи не зберігаємо пароль у відкритому вигляді (Plain text)!
 	// Це вимога безпеки. Використовуємо bcrypt.
@@ -48,59 +97,205 @@ func (s *AuthService) CreateUser(user models.User) (int, error) {
 	// Замінюємо чистий пароль на хеш перед збереженням у БД
 	user.Password = hash
 
+	// Роль при реєстрації завжди customer — клієнт не може призначити собі admin
+	user.Role = models.RoleCustomer
+
 	// 2. Виклик репозиторія для запису в БД
-	return s.repo.CreateUser(user)
+	id, err := s.repo.CreateUser(user)
+	if err != nil {
+		return 0, err
+	}
+
+	// 3. Лист з підтвердженням email. Реєстрація вже відбулась, тому помилку
+	// лише логуємо - користувач зможе запросити лист повторно
+	if err := s.SendVerificationEmail(ctx, id); err != nil {
+		logging.FromContext(ctx).Warn("verification email failed", "user_id", id, "error", err)
+	}
+
+	return id, nil
 }
 
-// GenerateToken - перевіряє дані входу і повертає JWT токен
-func (s *AuthService) GenerateToken(email, password string) (string, error) {
+// ErrInvalidCredentials - невірний email або пароль (не уточнюємо, що саме)
+var ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "невірний логін або пароль")
+
+// ErrWrongPassword - невірний пароль при підтвердженні дії вже авторизованим користувачем
+var ErrWrongPassword = apperror.Unauthorized("wrong_password", "невірний пароль")
+
+// GenerateToken - перевіряє дані входу і повертає пару access/refresh токенів.
+// Якщо з акаунта або IP було забагато невдалих спроб, повертає *LoginLockedError.
+func (s *AuthService) GenerateToken(ctx context.Context, email, password, clientIP string) (Tokens, error) {
+	// 0. Чи не заблоковано вхід (до bcrypt, щоб не витрачати на нього CPU)
+	if err := s.guard.Check(ctx, email, clientIP); err != nil {
+		return Tokens{}, err
+	}
+
 	// 1. Отримуємо користувача з БД за email
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
 		// Не уточнюємо, що саме не так (безпека), просто "користувача не знайдено" або помилка
-		return "", errors.New("невірний логін або пароль")
+		return Tokens{}, s.loginFailed(ctx, email, clientIP)
 	}
 
 	// 2. Порівнюємо хеш пароля з БД і введений пароль
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("невірний логін або пароль")
+		return Tokens{}, s.loginFailed(ctx, email, clientIP)
+	}
+
+	// 3. Видаємо токени (або challenge-токен, якщо увімкнено 2FA)
+	tokens, err := s.completeLogin(user)
+	if err != nil {
+		return Tokens{}, err
 	}
 
-	// 3. Генеруємо JWT токен
-	// Створюємо claims (корисне навантаження токена)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		UserID: user.ID, // Зашиваємо ID користувача в токен
+	// З 2FA лічильник скидаємо лише після вірного коду, інакше, знаючи пароль,
+	// можна було б перебирати коди без обмежень
+	if !tokens.TwoFactorRequired {
+		if err := s.guard.Success(ctx, email); err != nil {
+			logging.FromContext(ctx).Warn("login guard reset failed", "user_id", user.ID, "error", err)
+		}
+	}
+
+	return tokens, nil
+}
+
+// loginFailed - рахує невдалу спробу; помилка сховища лічильників не має
+// маскувати відповідь "невірний логін або пароль"
+func (s *AuthService) loginFailed(ctx context.Context, email, clientIP string) error {
+	if err := s.guard.Fail(ctx, email, clientIP); err != nil {
+		logging.FromContext(ctx).Warn("login guard update failed", "error", err)
+	}
+	return ErrInvalidCredentials
+}
+
+// RefreshTokens - обмінює refresh-токен на нову пару (старий токен відкликається).
+// Повторне використання вже відкликаного токена означає, що його викрали,
+// тому в такому разі завершуємо всі сесії користувача.
+func (s *AuthService) RefreshTokens(refreshToken string) (Tokens, error) {
+	hash := hashToken(refreshToken)
+
+	stored, err := s.repo.GetRefreshToken(hash)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if stored.RevokedAt != nil {
+		if err := s.repo.RevokeAllRefreshTokens(stored.UserID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.repo.RevokeRefreshToken(hash)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !revoked {
+		// Паралельний запит уже використав цей токен
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetUserByID(stored.UserID)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	authTime := time.Time{}
+	if stored.AuthTime != nil {
+		authTime = *stored.AuthTime
+	}
+	return s.issueTokens(user, authTime)
+}
+
+// Logout - відкликає refresh-токен поточної сесії (повторний виклик - не помилка)
+func (s *AuthService) Logout(refreshToken string) error {
+	_, err := s.repo.RevokeRefreshToken(hashToken(refreshToken))
+	return err
+}
+
+// issueTokens - підписує access-токен і створює новий refresh-токен.
+// authTime - час входу: щойно після пароля/OIDC/2FA це now, при оновленні - час з сесії.
+func (s *AuthService) issueTokens(user models.User, authTime time.Time) (Tokens, error) {
+	// Створюємо claims (корисне навантаження токена) і підписуємо активним ключем
+	claims := &tokenClaims{
+		UserID: user.ID,   // Зашиваємо ID користувача в токен
+		Role:   user.Role, // і його роль (для RequireRole)
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)), // Токен протухне через 12 годин
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-	})
+	}
+	if !authTime.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(authTime)
+	}
+	accessToken, err := s.keys.Sign(claims)
+	if err != nil {
+		return Tokens{}, err
+	}
 
-	// Підписуємо токен секретним ключем
-	return token.SignedString([]byte(signingKey))
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+	if err := s.repo.CreateRefreshToken(user.ID, hashToken(refreshToken), time.Now().Add(s.refreshTTL), authTime); err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
 }
 
-// ParseToken - розшифровує токен і повертає ID користувача
+// ParseToken - розшифровує токен і повертає ID та роль користувача
 // Цей метод знадобиться для Middleware (перевірка доступу до захищених сторінок)
-func (s *AuthService) ParseToken(accessToken string) (int, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(signingKey), nil
-	})
+func (s *AuthService) ParseToken(accessToken string) (int, string, error) {
+	claims, err := s.ParseAccessToken(accessToken)
+	if err != nil {
+		return 0, "", err
+	}
+	return claims.UserID, claims.Role, nil
+}
+
+// ParseAccessToken - як ParseToken, але з часом входу (для дій, що потребують свіжого входу)
+func (s *AuthService) ParseAccessToken(accessToken string) (AccessClaims, error) {
+	// Ключ обирається за заголовком kid, тож токени старих ключів теж проходять
+	token, err := s.keys.Parse(accessToken, &tokenClaims{})
 
 	if err != nil {
-		return 0, err
+		return AccessClaims{}, err
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("token claims are not of type *tokenClaims")
+		return AccessClaims{}, errors.New("token claims are not of type *tokenClaims")
+	}
+	// Тим самим ключем підписуються й інші токени (напр. стан входу OIDC) - вони без user_id
+	if claims.UserID <= 0 {
+		return AccessClaims{}, errors.New("token has no user id")
 	}
 
-	return claims.UserID, nil
+	result := AccessClaims{UserID: claims.UserID, Role: claims.Role}
+	// Токени, видані до появи ролей, не містять role — вважаємо їх customer
+	if result.Role == "" {
+		result.Role = models.RoleCustomer
+	}
+	if claims.AuthTime != nil {
+		result.AuthTime = claims.AuthTime.Time
+	}
+
+	return result, nil
 }
 
 // GetUserByID - Отримання інформації про користувача за ID
@@ -108,41 +303,116 @@ func (s *AuthService) GetUserByID(userID int) (models.User, error) {
 	return s.repo.GetUserByID(userID)
 }
 
-// ChangePassword - Зміна пароля користувача
-func (s *AuthService) ChangePassword(userID int, oldPassword, newPassword string) error {
-	// Перевіряємо формат нового пароля
-	if len(newPassword) < 6 {
-		return errors.New("новий пароль повинен бути мінімум 6 символів")
+// ChangePassword - Зміна пароля користувача.
+// Усі існуючі сесії відкликаються, а поточна отримує нову пару токенів.
+func (s *AuthService) ChangePassword(userID int, oldPassword, newPassword string) (Tokens, error) {
+	// Перевіряємо новий пароль за тією ж політикою, що й при реєстрації
+	if err := validatePassword("new_password", newPassword); err != nil {
+		return Tokens{}, err
 	}
 
 	// Отримуємо користувача для перевірки старого пароля
 	user, err := s.repo.GetUserByID(userID)
 	if err != nil {
-		return err
+		return Tokens{}, err
 	}
 
 	// Перевіряємо старий пароль
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
 	if err != nil {
-		return errors.New("неправильний поточний пароль")
+		return Tokens{}, ErrWrongPassword
 	}
 
 	// Хешуємо новий пароль
 	newHash, err := generatePasswordHash(newPassword)
 	if err != nil {
-		return err
+		return Tokens{}, err
 	}
 
 	// Оновлюємо пароль у репозиторії
-	return s.repo.UpdatePassword(userID, newHash)
+	if err := s.repo.UpdatePassword(userID, newHash); err != nil {
+		return Tokens{}, err
+	}
+
+	// Пароль змінено - всі інші пристрої мають увійти заново
+	if err := s.repo.RevokeAllRefreshTokens(userID); err != nil {
+		return Tokens{}, err
+	}
+
+	return s.issueTokens(user, time.Now())
 }
 
-// UpdateProfile - оновлює ім'я та email користувача
+// UpdateProfile - оновлює ім'я та email користувача.
+// Порожнє поле означає "не змінювати", а не "стерти".
 func (s *AuthService) UpdateProfile(userID int, name, email string) error {
-	// Можна додати валідацію email тут при потребі
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	email = validation.NormalizeEmail(email)
+
+	errs := validation.Errors{}
+	if name != "" {
+		errs.Check("name", validation.Name(name))
+	} else {
+		name = user.Name
+	}
+	if email != "" {
+		errs.Check("email", validation.Email(email))
+	} else {
+		email = user.Email
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	if other, err := s.repo.GetUserByEmail(email); err == nil && other.ID != userID {
+		return repository.ErrEmailTaken
+	}
+
 	return s.repo.UpdateProfile(userID, name, email)
 }
 
+// ErrUnknownRole - роль не входить до models.Roles
+var ErrUnknownRole = &apperror.Error{
+	Kind:    apperror.ErrValidation,
+	Code:    "unknown_role",
+	Message: "невідома роль",
+	Field:   "role",
+}
+
+// SetUserRole - змінює роль користувача (доступно лише адміністратору)
+func (s *AuthService) SetUserRole(userID int, role string) error {
+	if !models.IsValidRole(role) {
+		return ErrUnknownRole
+	}
+	return s.repo.UpdateRole(userID, role)
+}
+
+// validatePassword - спільна політика паролів; помилка прив'язується до поля field
+func validatePassword(field, password string) error {
+	errs := validation.Errors{}
+	errs.Check(field, validation.Password(password))
+	return errs.Err()
+}
+
+// generateRandomToken - криптостійкий випадковий токен (32 байти, base64url)
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken - SHA-256 від токена; у БД зберігаємо тільки хеш
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // generatePasswordHash - допоміжна функція для хешування пароля
 func generatePasswordHash(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
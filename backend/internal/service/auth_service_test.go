@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// loggedInUser - користувач з паролем "password1" і однією сесією
+func loggedInUser(t *testing.T) (*AuthService, *fakeAuthRepo, models.User, Tokens) {
+	t.Helper()
+
+	repo := newFakeAuthRepo()
+	auth := newTestAuthService(t, repo)
+
+	hash, err := generatePasswordHash("password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := repo.addUser(models.User{Name: "Олена", Email: "olena@example.com", Password: hash})
+
+	tokens, err := auth.issueTokens(user, time.Now().Add(-time.Hour).Truncate(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth, repo, user, tokens
+}
+
+func TestRefreshTokensRotation(t *testing.T) {
+	auth, repo, user, first := loggedInUser(t)
+	firstClaims, err := auth.ParseAccessToken(first.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := auth.RefreshTokens(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token was not rotated")
+	}
+	if repo.activeSessions(user.ID) != 1 {
+		t.Errorf("%d active sessions, want 1", repo.activeSessions(user.ID))
+	}
+
+	// Оновлення не є новим входом: час входу переходить з сесії
+	claims, err := auth.ParseAccessToken(second.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != user.ID || claims.AuthTime.IsZero() || !claims.AuthTime.Equal(firstClaims.AuthTime) {
+		t.Errorf("claims after refresh = %+v, want auth_time %v", claims, firstClaims.AuthTime)
+	}
+
+	if _, err := auth.RefreshTokens(second.RefreshToken); err != nil {
+		t.Errorf("rotated token rejected: %v", err)
+	}
+}
+
+func TestRefreshTokensReuse(t *testing.T) {
+	auth, repo, user, first := loggedInUser(t)
+
+	second, err := auth.RefreshTokens(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Друга сесія (інший пристрій) теж має завершитися
+	if _, err := auth.issueTokens(user, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Старий токен пред'явлено вдруге - його викрали, завершуємо всі сесії
+	if _, err := auth.RefreshTokens(first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reused token: err = %v", err)
+	}
+	if n := repo.activeSessions(user.ID); n != 0 {
+		t.Errorf("%d sessions left after reuse, want 0", n)
+	}
+	if _, err := auth.RefreshTokens(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("legitimate token after reuse: err = %v", err)
+	}
+}
+
+// raceRepo - паралельний запит відкликає токен між читанням і відкликанням
+type raceRepo struct {
+	*fakeAuthRepo
+}
+
+func (r raceRepo) GetRefreshToken(tokenHash string) (models.RefreshToken, error) {
+	rt, err := r.fakeAuthRepo.GetRefreshToken(tokenHash)
+	r.fakeAuthRepo.RevokeRefreshToken(tokenHash)
+	return rt, err
+}
+
+func TestRefreshTokensConcurrentUse(t *testing.T) {
+	_, repo, user, tokens := loggedInUser(t)
+	other, err := newTestAuthService(t, repo).issueTokens(user, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuthService(raceRepo{repo}, newTestKeyRing(t), nil, "http://app.test", nil, 15*time.Minute, time.Hour)
+	if _, err := auth.RefreshTokens(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Це гонка двох вкладок, а не крадіжка: інші сесії лишаються
+	if rt := repo.refresh[hashToken(other.RefreshToken)]; rt.RevokedAt != nil {
+		t.Error("concurrent refresh revoked other sessions")
+	}
+}
+
+func TestRefreshTokensInvalid(t *testing.T) {
+	auth, repo, _, tokens := loggedInUser(t)
+
+	if _, err := auth.RefreshTokens("unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: err = %v", err)
+	}
+
+	repo.refresh[hashToken(tokens.RefreshToken)].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := auth.RefreshTokens(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expired token: err = %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	auth, _, _, tokens := loggedInUser(t)
+
+	if err := auth.Logout(tokens.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.Logout(tokens.RefreshToken); err != nil {
+		t.Errorf("second logout: %v", err)
+	}
+	if _, err := auth.RefreshTokens(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after logout: err = %v", err)
+	}
+}
+
+func TestChangePasswordRevokesSessions(t *testing.T) {
+	auth, repo, user, old := loggedInUser(t)
+
+	if _, err := auth.ChangePassword(user.ID, "wrong-password1", "newpassword2"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("wrong current password: err = %v", err)
+	}
+	if repo.activeSessions(user.ID) != 1 {
+		t.Fatal("failed password change revoked sessions")
+	}
+
+	fresh, err := auth.ChangePassword(user.ID, "password1", "newpassword2")
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	// Лишається лише нова сесія, видана у відповідь на зміну пароля
+	if repo.activeSessions(user.ID) != 1 {
+		t.Errorf("%d active sessions, want 1", repo.activeSessions(user.ID))
+	}
+	if _, err := auth.RefreshTokens(fresh.RefreshToken); err != nil {
+		t.Errorf("new session rejected: %v", err)
+	}
+	if _, err := auth.RefreshTokens(old.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("session from before the change: err = %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
+)
+
+// Типи значень у specs
+const (
+	specString = "string"
+	specNumber = "number"
+)
+
+// specSchemas - обов'язкові поля specs для кожної категорії.
+// Саме ці поля читають CompatibilityService та RecommendationService.
+var specSchemas = map[string]map[string]string{
+	"cpu": {
+		"socket": specString,
+		"tdp":    specNumber,
+		"score":  specNumber,
+	},
+	"gpu": {
+		"tdp":   specNumber,
+		"score": specNumber,
+	},
+	"motherboard": {
+		"socket":      specString,
+		"memory_type": specString,
+	},
+	"ram": {
+		"type": specString,
+	},
+	"psu": {
+		"wattage": specNumber,
+	},
+}
+
+// CatalogService - керування каталогом компонентів (для адміністратора)
+type CatalogService struct {
+	repo repository.ComponentRepository
+}
+
+func NewCatalogService(repo repository.ComponentRepository) *CatalogService {
+	return &CatalogService{repo: repo}
+}
+
+// CreateComponent - валідує і додає новий компонент
+func (s *CatalogService) CreateComponent(ctx context.Context, c models.Component) (int, error) {
+	if err := ValidateComponent(&c); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+// UpdateComponent - валідує і перезаписує існуючий компонент
+func (s *CatalogService) UpdateComponent(ctx context.Context, c models.Component) error {
+	if err := ValidateComponent(&c); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, c)
+}
+
+// DeleteComponent - м'яко видаляє компонент з каталогу
+func (s *CatalogService) DeleteComponent(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ValidateComponent - перевіряє основні поля та specs за схемою категорії.
+// Нормалізує категорію (нижній регістр) та назву (без пробілів по краях).
+func ValidateComponent(c *models.Component) error {
+	fields := validation.Errors{}
+
+	c.Name = strings.TrimSpace(c.Name)
+	c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+
+	if c.Name == "" {
+		fields["name"] = "назва обов'язкова"
+	}
+	if c.Price <= 0 {
+		fields["price"] = "ціна має бути більшою за 0"
+	}
+
+	schema, ok := specSchemas[c.Category]
+	if !ok {
+		fields["category"] = fmt.Sprintf("невідома категорія %q", c.Category)
+	}
+
+	var specs map[string]interface{}
+	if len(c.Specs) == 0 {
+		specs = map[string]interface{}{}
+		c.Specs = json.RawMessage(`{}`)
+	} else if err := json.Unmarshal(c.Specs, &specs); err != nil || specs == nil {
+		fields["specs"] = "specs має бути JSON-об'єктом"
+	}
+
+	if ok && specs != nil {
+		for name, kind := range schema {
+			value, present := specs[name]
+			if !present || value == nil {
+				fields["specs."+name] = "обов'язкове поле"
+				continue
+			}
+			switch kind {
+			case specString:
+				if str, isStr := value.(string); !isStr || strings.TrimSpace(str) == "" {
+					fields["specs."+name] = "має бути непорожнім рядком"
+				}
+			case specNumber:
+				if num, isNum := value.(float64); !isNum || num < 0 {
+					fields["specs."+name] = "має бути невід'ємним числом"
+				}
+			}
+		}
+	}
+
+	return fields.Err()
+}
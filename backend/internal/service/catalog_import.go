@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
+)
+
+// Формати прайс-листів
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+var (
+	// ErrImportHasErrors - прайс-лист містить некоректні рядки, нічого не записано
+	ErrImportHasErrors = apperror.Validation("import_has_errors", "прайс-лист містить помилки, імпорт скасовано")
+	// ErrImportMalformed - файл неможливо розібрати (формат, кодування, структура)
+	ErrImportMalformed = apperror.Validation("import_malformed", "некоректний прайс-лист")
+)
+
+// ColumnMapping - відповідність колонок прайс-листа полям models.Component.
+// Specs: ключ у specs -> назва колонки. Колонки з префіксом "spec_" або "specs."
+// потрапляють у specs автоматично (без префікса).
+type ColumnMapping struct {
+	SKU      string
+	Name     string
+	Category string
+	Price    string
+	ImageURL string
+	Specs    map[string]string
+}
+
+// DefaultColumnMapping - колонки з тими самими назвами, що й поля JSON компонента
+func DefaultColumnMapping() ColumnMapping {
+	return ColumnMapping{
+		SKU:      "sku",
+		Name:     "name",
+		Category: "category",
+		Price:    "price",
+		ImageURL: "image_url",
+		Specs:    map[string]string{},
+	}
+}
+
+// ParseColumnMapping - розбирає рядок виду "sku=Артикул,price=Ціна,specs.socket=Сокет"
+// поверх стандартної відповідності
+func ParseColumnMapping(s string) (ColumnMapping, error) {
+	m := DefaultColumnMapping()
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return m, fmt.Errorf("некоректна відповідність колонок: %q", pair)
+		}
+
+		switch field {
+		case "sku":
+			m.SKU = column
+		case "name":
+			m.Name = column
+		case "category":
+			m.Category = column
+		case "price":
+			m.Price = column
+		case "image_url":
+			m.ImageURL = column
+		default:
+			key, isSpec := strings.CutPrefix(field, "specs.")
+			if !isSpec || key == "" {
+				return m, fmt.Errorf("невідоме поле %q (очікується sku, name, category, price, image_url або specs.<ключ>)", field)
+			}
+			m.Specs[key] = column
+		}
+	}
+
+	return m, nil
+}
+
+// ImportOptions - параметри одного запуску імпорту
+type ImportOptions struct {
+	Format    string // csv або json
+	Delimiter rune   // Роздільник CSV, за замовчуванням ','
+	Mapping   ColumnMapping
+	DryRun    bool // Лише порахувати diff, нічого не записувати
+}
+
+// CatalogImportService - імпорт прайс-листів постачальника у каталог
+type CatalogImportService struct {
+	repo repository.CatalogImport
+}
+
+func NewCatalogImportService(repo repository.CatalogImport) *CatalogImportService {
+	return &CatalogImportService{repo: repo}
+}
+
+// Import - розбирає прайс-лист, валідує кожен рядок і робить upsert за SKU.
+// Якщо хоч один рядок некоректний, повертає звіт з помилками та ErrImportHasErrors.
+func (s *CatalogImportService) Import(ctx context.Context, r io.Reader, opts ImportOptions) (models.ImportReport, error) {
+	report := models.ImportReport{DryRun: opts.DryRun, Changes: []models.ImportChange{}}
+
+	var records []map[string]interface{}
+	var err error
+	switch strings.ToLower(opts.Format) {
+	case ImportFormatCSV:
+		records, err = readCSVRecords(r, opts.Delimiter)
+	case ImportFormatJSON:
+		records, err = readJSONRecords(r)
+	default:
+		return report, fmt.Errorf("%w: непідтримуваний формат %q (csv або json)", ErrImportMalformed, opts.Format)
+	}
+	if err != nil {
+		// Причину теж загортаємо: хендлер відрізняє перевищення розміру тіла (413)
+		return report, fmt.Errorf("%w: %w", ErrImportMalformed, err)
+	}
+	if len(records) == 0 {
+		return report, fmt.Errorf("%w: файл не містить жодного рядка", ErrImportMalformed)
+	}
+
+	items := make([]models.ImportItem, 0, len(records))
+	seen := make(map[string]int)
+
+	for i, record := range records {
+		// Рядок 1 у CSV - заголовок, тому дані починаються з рядка 2
+		row := i + 1
+		if opts.Format == ImportFormatCSV {
+			row = i + 2
+		}
+
+		item, rowErr := buildImportItem(record, opts.Mapping)
+		item.Row = row
+		if rowErr != nil {
+			rowErr.Row = row
+			report.Errors = append(report.Errors, *rowErr)
+			continue
+		}
+
+		if first, dup := seen[item.SupplierSKU]; dup {
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row:         row,
+				SupplierSKU: item.SupplierSKU,
+				Message:     fmt.Sprintf("SKU вже зустрічався у рядку %d", first),
+			})
+			continue
+		}
+		seen[item.SupplierSKU] = row
+
+		items = append(items, item)
+	}
+
+	report.Total = len(records)
+	if len(report.Errors) > 0 {
+		return report, ErrImportHasErrors
+	}
+
+	return s.repo.ImportComponents(ctx, items, opts.DryRun)
+}
+
+// buildImportItem - перетворює один запис прайс-листа на компонент
+func buildImportItem(record map[string]interface{}, m ColumnMapping) (models.ImportItem, *models.ImportRowError) {
+	var item models.ImportItem
+	used := map[string]bool{}
+
+	get := func(column string) interface{} {
+		key := normalizeColumn(column)
+		used[key] = true
+		return record[key]
+	}
+
+	item.SupplierSKU = strings.TrimSpace(toString(get(m.SKU)))
+	if item.SupplierSKU == "" {
+		return item, &models.ImportRowError{Message: "відсутній SKU"}
+	}
+
+	c := models.Component{
+		Name:     toString(get(m.Name)),
+		Category: strings.ToLower(strings.TrimSpace(toString(get(m.Category)))),
+		ImageURL: strings.TrimSpace(toString(get(m.ImageURL))),
+	}
+
+	price, err := toFloat(get(m.Price))
+	if err != nil {
+		return item, &models.ImportRowError{SupplierSKU: item.SupplierSKU, Message: "некоректна ціна", Fields: map[string]string{"price": err.Error()}}
+	}
+	c.Price = price
+
+	specs := map[string]interface{}{}
+
+	// JSON-фід може містити готовий об'єкт specs
+	if raw, ok := record["specs"].(map[string]interface{}); ok {
+		used["specs"] = true
+		for k, v := range raw {
+			specs[k] = v
+		}
+	}
+
+	for key, column := range m.Specs {
+		if v := get(column); v != nil {
+			specs[key] = v
+		}
+	}
+
+	for column, v := range record {
+		if used[column] {
+			continue
+		}
+		for _, prefix := range []string{"spec_", "specs."} {
+			if key, ok := strings.CutPrefix(column, prefix); ok && key != "" {
+				specs[key] = v
+			}
+		}
+	}
+
+	schema := specSchemas[c.Category]
+	for key, v := range specs {
+		converted, ok := convertSpecValue(v, schema[key])
+		if !ok {
+			delete(specs, key)
+			continue
+		}
+		specs[key] = converted
+	}
+
+	c.Specs, _ = json.Marshal(specs)
+
+	if err := ValidateComponent(&c); err != nil {
+		var verrs validation.Errors
+		if errors.As(err, &verrs) {
+			return item, &models.ImportRowError{SupplierSKU: item.SupplierSKU, Message: "некоректний компонент", Fields: verrs}
+		}
+		return item, &models.ImportRowError{SupplierSKU: item.SupplierSKU, Message: err.Error()}
+	}
+
+	item.Component = c
+	return item, nil
+}
+
+// convertSpecValue - приводить значення до типу зі схеми категорії.
+// Невідомі ключі: числовий рядок стає числом, інше лишається рядком.
+// Порожні значення пропускаються (ok == false).
+func convertSpecValue(v interface{}, kind string) (interface{}, bool) {
+	str, isStr := v.(string)
+	if isStr {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			return nil, false
+		}
+	}
+	if v == nil {
+		return nil, false
+	}
+
+	switch kind {
+	case specString:
+		return toString(v), true
+	case specNumber:
+		if f, err := toFloat(v); err == nil {
+			return f, true
+		}
+		// Лишаємо як є, щоб ValidateComponent показав зрозумілу помилку
+		return v, true
+	}
+
+	if isStr {
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return f, true
+		}
+		return str, true
+	}
+	return v, true
+}
+
+// readCSVRecords - читає CSV з рядком заголовка; назви колонок нормалізуються
+func readCSVRecords(r io.Reader, delimiter rune) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(r)
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("помилка читання заголовка CSV: %w", err)
+	}
+	for i := range header {
+		header[i] = normalizeColumn(header[i])
+	}
+
+	var records []map[string]interface{}
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("помилка читання CSV: %w", err)
+		}
+
+		record := make(map[string]interface{}, len(header))
+		for i, value := range line {
+			if i < len(header) {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// readJSONRecords - читає JSON-масив об'єктів
+func readJSONRecords(r io.Reader) ([]map[string]interface{}, error) {
+	var raw []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("некоректний JSON (очікується масив об'єктів): %w", err)
+	}
+
+	records := make([]map[string]interface{}, len(raw))
+	for i, obj := range raw {
+		record := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			record[normalizeColumn(k)] = v
+		}
+		records[i] = record
+	}
+
+	return records, nil
+}
+
+// normalizeColumn - назви колонок порівнюються без регістру, пробілів і BOM
+func normalizeColumn(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s, "\ufeff")))
+}
+
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// toFloat - приймає числа та рядки на кшталт "1 299,90".
+// Тисячі можна відділяти лише пробілом. Кома вважається десятковою, якщо вона
+// єдиний роздільник і після неї не рівно три цифри: "1,299" може означати і 1299,
+// і 1.299, тож такі значення (як і "1,299.90") відхиляємо, а не вгадуємо.
+func toFloat(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case string:
+		s := strings.ReplaceAll(strings.TrimSpace(val), " ", "")
+		s = strings.ReplaceAll(s, "\u00a0", "")
+		if s == "" {
+			return 0, errors.New("порожнє значення")
+		}
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			fraction := s[i+1:]
+			if strings.ContainsAny(fraction, ",.") || strings.Contains(s[:i], ".") || (len(fraction) == 3 && isDigits(fraction)) {
+				return 0, fmt.Errorf("неоднозначне число %q: відділяйте тисячі пробілом, а дробову частину - крапкою або комою", val)
+			}
+			s = s[:i] + "." + fraction
+		}
+		return strconv.ParseFloat(s, 64)
+	default:
+		return 0, fmt.Errorf("очікується число, отримано %v", v)
+	}
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pc-configurator/internal/models"
+)
+
+// fakeCatalogImport - запам'ятовує, що сервіс передав у репозиторій
+type fakeCatalogImport struct {
+	items []models.ImportItem
+	calls int
+}
+
+func (f *fakeCatalogImport) ImportComponents(ctx context.Context, items []models.ImportItem, dryRun bool) (models.ImportReport, error) {
+	f.calls++
+	f.items = items
+	return models.ImportReport{DryRun: dryRun, Total: len(items)}, nil
+}
+
+func TestToFloat(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		want    float64
+		wantErr bool
+	}{
+		{float64(1299.9), 1299.9, false},
+		{"1299", 1299, false},
+		{"1299.90", 1299.9, false},
+		{"1299,90", 1299.9, false},
+		{"12,5", 12.5, false},
+		{"0,0001", 0.0001, false},
+		{" 1 299,90 ", 1299.9, false},
+		{"1 299", 1299, false},
+		{"12 345 678", 12345678, false},
+
+		// Кома перед трьома цифрами - роздільник тисяч чи дробу, не вгадуємо
+		{"1,299", 0, true},
+		{"1,299.90", 0, true},
+		{"1.299,90", 0, true},
+		{"1,299,000", 0, true},
+		{"", 0, true},
+		{"дорого", 0, true},
+		{true, 0, true},
+		{nil, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := toFloat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("toFloat(%#v) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseColumnMapping(t *testing.T) {
+	m, err := ParseColumnMapping(" sku = Артикул, price=Ціна ,specs.socket=Сокет")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultColumnMapping()
+	want.SKU, want.Price = "Артикул", "Ціна"
+	want.Specs["socket"] = "Сокет"
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("mapping = %+v, want %+v", m, want)
+	}
+
+	for _, bad := range []string{"sku", "sku=", "=Артикул", "weight=Вага", "specs.=Сокет"} {
+		if _, err := ParseColumnMapping(bad); err == nil {
+			t.Errorf("ParseColumnMapping(%q) accepted", bad)
+		}
+	}
+}
+
+func specsOf(t *testing.T, item models.ImportItem) map[string]interface{} {
+	t.Helper()
+	var specs map[string]interface{}
+	if err := json.Unmarshal(item.Component.Specs, &specs); err != nil {
+		t.Fatalf("specs of %s: %v", item.SupplierSKU, err)
+	}
+	return specs
+}
+
+func TestImportCSV(t *testing.T) {
+	repo := &fakeCatalogImport{}
+	s := NewCatalogImportService(repo)
+
+	mapping, err := ParseColumnMapping("sku=Артикул,name=Назва,category=Категорія,price=Ціна,specs.socket=Сокет")
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := "\ufeffАртикул;Назва;Категорія;Ціна;Сокет;spec_tdp;specs.score;spec_wattage;Склад\n" +
+		"CPU-1; Ryzen 5 7600 ;CPU;8 999,90;AM5;65;1500;;12\n" +
+		"PSU-1;Be Quiet 650W;psu;2500;;;;650;3\n"
+
+	report, err := s.Import(context.Background(), strings.NewReader(csv), ImportOptions{
+		Format: ImportFormatCSV, Delimiter: ';', Mapping: mapping, DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Import: %v (%+v)", err, report.Errors)
+	}
+	if !report.DryRun || len(repo.items) != 2 {
+		t.Fatalf("report %+v, items %+v", report, repo.items)
+	}
+
+	cpu := repo.items[0]
+	if cpu.Row != 2 || cpu.SupplierSKU != "CPU-1" {
+		t.Errorf("row %d, sku %q", cpu.Row, cpu.SupplierSKU)
+	}
+	if c := cpu.Component; c.Name != "Ryzen 5 7600" || c.Category != "cpu" || c.Price != 8999.9 {
+		t.Errorf("component = %+v", c)
+	}
+	// Числові поля схеми стають числами, порожні клітинки пропущено,
+	// колонки без відповідності ("Склад") не потрапляють у specs
+	if specs, want := specsOf(t, cpu), map[string]interface{}{"socket": "AM5", "tdp": 65.0, "score": 1500.0}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("specs = %v, want %v", specs, want)
+	}
+	if specs, want := specsOf(t, repo.items[1]), map[string]interface{}{"wattage": 650.0}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("psu specs = %v, want %v", specs, want)
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	repo := &fakeCatalogImport{}
+	s := NewCatalogImportService(repo)
+
+	feed := `[
+		{"SKU": "GPU-1", "name": "RTX 4060", "category": "gpu", "price": 12999.5,
+		 "specs": {"tdp": "115", "score": 9000}, "spec_vram": "8", "image_url": " https://img.test/4060.png "},
+		{"sku": 42, "name": "DDR5 32GB", "category": "ram", "price": "3 499", "specs.type": "DDR5"}
+	]`
+	if _, err := s.Import(context.Background(), strings.NewReader(feed), ImportOptions{Format: ImportFormatJSON, Mapping: DefaultColumnMapping()}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(repo.items) != 2 {
+		t.Fatalf("items = %+v", repo.items)
+	}
+
+	gpu := repo.items[0]
+	if gpu.Row != 1 || gpu.Component.Price != 12999.5 || gpu.Component.ImageURL != "https://img.test/4060.png" {
+		t.Errorf("gpu = %+v", gpu)
+	}
+	// Ключ поза схемою категорії, схожий на число, теж стає числом
+	if specs, want := specsOf(t, gpu), map[string]interface{}{"tdp": 115.0, "score": 9000.0, "vram": 8.0}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("gpu specs = %v, want %v", specs, want)
+	}
+
+	ram := repo.items[1]
+	if ram.SupplierSKU != "42" || ram.Component.Price != 3499 {
+		t.Errorf("ram = %+v", ram)
+	}
+	if specs := specsOf(t, ram); specs["type"] != "DDR5" {
+		t.Errorf("ram specs = %v", specs)
+	}
+}
+
+func TestImportRowErrors(t *testing.T) {
+	repo := &fakeCatalogImport{}
+	s := NewCatalogImportService(repo)
+
+	csv := "sku,name,category,price,spec_wattage\n" +
+		"PSU-1,Be Quiet 650W,psu,\"1,299\",650\n" + // Неоднозначна ціна
+		",Без артикула,psu,1000,650\n" +
+		"PSU-2,Corsair 750W,psu,3000,750\n" +
+		"PSU-2,Corsair 750W,psu,3000,750\n" +
+		"CPU-1,Ryzen 5 7600,cpu,9000,\n" // Немає обов'язкових specs
+
+	report, err := s.Import(context.Background(), strings.NewReader(csv), ImportOptions{Format: ImportFormatCSV, Mapping: DefaultColumnMapping()})
+	if !errors.Is(err, ErrImportHasErrors) {
+		t.Fatalf("err = %v, want ErrImportHasErrors", err)
+	}
+	if repo.calls != 0 {
+		t.Error("repository called despite row errors")
+	}
+	if report.Total != 5 {
+		t.Errorf("total = %d, want 5", report.Total)
+	}
+
+	var rows []int
+	for _, e := range report.Errors {
+		rows = append(rows, e.Row)
+	}
+	if want := []int{2, 3, 5, 6}; !reflect.DeepEqual(rows, want) {
+		t.Fatalf("error rows %v, want %v (%+v)", rows, want, report.Errors)
+	}
+	if e := report.Errors[0]; e.SupplierSKU != "PSU-1" || e.Fields["price"] == "" {
+		t.Errorf("price error = %+v", e)
+	}
+	if e := report.Errors[3]; e.Fields["specs.socket"] == "" {
+		t.Errorf("cpu error = %+v", e)
+	}
+
+	for _, tt := range []struct{ format, body string }{
+		{ImportFormatCSV, ""},
+		{ImportFormatJSON, `{"sku": "not an array"}`},
+		{"xml", "<items/>"},
+	} {
+		if _, err := s.Import(context.Background(), strings.NewReader(tt.body), ImportOptions{Format: tt.format}); !errors.Is(err, ErrImportMalformed) {
+			t.Errorf("%s %q: err = %v, want ErrImportMalformed", tt.format, tt.body, err)
+		}
+	}
+}
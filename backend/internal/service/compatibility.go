@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"pc-configurator/internal/metrics"
 	"pc-configurator/internal/models"
 	"pc-configurator/internal/repository"
 )
@@ -44,21 +45,55 @@ func NewCompatibilityService(repo repository.ComponentRepository) *Compatibility
 type ValidationResult struct {
 	IsValid  bool     `json:"is_valid"`
 	Messages []string `json:"messages"`
+
+	failedRules []string // Правила, які не пройдено (для метрик), кожне один раз
+}
+
+// fail - збірка не пройшла правило rule
+func (r *ValidationResult) fail(rule, message string) {
+	r.IsValid = false
+	r.Messages = append(r.Messages, message)
+	for _, existing := range r.failedRules {
+		if existing == rule {
+			return
+		}
+	}
+	r.failedRules = append(r.failedRules, rule)
 }
 
 func (s *CompatibilityService) ValidateBuild(ctx context.Context, componentIDs []int) (ValidationResult, error) {
 	result := ValidationResult{IsValid: true, Messages: []string{}}
 
-	// Отримання компонентів
-	var components []models.Component
-	for _, id := range componentIDs {
-		comp, err := s.repo.GetByID(ctx, id)
-		if err != nil {
-			return result, err
-		}
-		components = append(components, *comp)
+	// Отримання компонентів одним запитом
+	components, err := s.repo.GetByIDs(ctx, componentIDs)
+	if err != nil {
+		return result, err
+	}
+
+	result = checkCompatibility(components)
+	for _, rule := range result.failedRules {
+		metrics.ValidationFailures.WithLabelValues(rule).Inc()
 	}
 
+	return result, nil
+}
+
+// checkCompatibility - перевірки сумісності без звернень до БД
+// (використовується і для лічильників сумісних деталей на сторінці компонента)
+// compatibilityPairs - для яких пар категорій у checkCompatibility є правило:
+// сокет (cpu-motherboard), тип пам'яті (motherboard-ram), потужність БЖ (psu-cpu/gpu).
+// Пара інших категорій сумісна завжди, тож рахувати для неї "сумісні" немає сенсу.
+var compatibilityPairs = map[string][]string{
+	"cpu":         {"motherboard", "psu"},
+	"motherboard": {"cpu", "ram"},
+	"ram":         {"motherboard"},
+	"gpu":         {"psu"},
+	"psu":         {"cpu", "gpu"},
+}
+
+func checkCompatibility(components []models.Component) ValidationResult {
+	result := ValidationResult{IsValid: true, Messages: []string{}}
+
 	// Сортування компонентів по типах
 	var cpu *models.Component
 	var mobo *models.Component
@@ -103,8 +138,7 @@ func (s *CompatibilityService) ValidateBuild(ctx context.Context, componentIDs [
 		moboSocket := strings.ToUpper(strings.TrimSpace(moboSpec.Socket))
 
 		if cpuSocket != "" && moboSocket != "" && !strings.EqualFold(cpuSocket, moboSocket) {
-			result.IsValid = false
-			result.Messages = append(result.Messages,
+			result.fail("socket",
 				fmt.Sprintf("Несумісність: Процесор %s (Socket %s) не підходить до плати %s (Socket %s)",
 					cpu.Name, cpuSpec.Socket, mobo.Name, moboSpec.Socket))
 		}
@@ -138,8 +172,7 @@ func (s *CompatibilityService) ValidateBuild(ctx context.Context, componentIDs [
 			if (moboHasDDR4 || moboHasDDR5) && (ramHasDDR4 || ramHasDDR5) {
 				// Якщо типи різні - це помилка
 				if (moboHasDDR4 && ramHasDDR5) || (moboHasDDR5 && ramHasDDR4) {
-					result.IsValid = false
-					result.Messages = append(result.Messages,
+					result.fail("memory_type",
 						fmt.Sprintf("Несумісність: Плата підтримує %s, а RAM - %s", moboSpec.MemoryType, ramSpec.Type))
 				}
 			}
@@ -155,8 +188,7 @@ func (s *CompatibilityService) ValidateBuild(ctx context.Context, componentIDs [
 		if psuSpec.Wattage > 0 {
 			recommended := float64(totalTDP) * 1.2
 			if float64(psuSpec.Wattage) < recommended {
-				result.IsValid = false
-				result.Messages = append(result.Messages,
+				result.fail("psu_wattage",
 					fmt.Sprintf("Слабкий БЖ: Треба %.0f Вт, є %d Вт", recommended, psuSpec.Wattage))
 			}
 		}
@@ -166,5 +198,5 @@ func (s *CompatibilityService) ValidateBuild(ctx context.Context, componentIDs [
 		result.Messages = append(result.Messages, "Збірка сумісна!")
 	}
 
-	return result, nil
+	return result
 }
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+)
+
+// Скільки схожих компонентів показувати на сторінці компонента
+const similarComponentsLimit = 5
+
+// similarityKeys - характеристики, збіг яких робить компоненти взаємозамінними
+// (процесор під той самий сокет, пам'ять того ж типу тощо)
+var similarityKeys = map[string]string{
+	"cpu":         "socket",
+	"motherboard": "socket",
+	"ram":         "type",
+}
+
+// ComponentDetailService - дані для сторінки окремого компонента
+type ComponentDetailService struct {
+	repo   repository.ComponentRepository
+	prices *PriceHistoryService
+}
+
+func NewComponentDetailService(repo repository.ComponentRepository, prices *PriceHistoryService) *ComponentDetailService {
+	return &ComponentDetailService{repo: repo, prices: prices}
+}
+
+// GetComponentDetail - компонент з характеристиками, підсумком цін,
+// кількістю сумісних деталей по категоріях і схожими товарами
+func (s *ComponentDetailService) GetComponentDetail(ctx context.Context, id int) (models.ComponentDetail, error) {
+	comp, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.ComponentDetail{}, err
+	}
+
+	detail := models.ComponentDetail{
+		Component:        *comp,
+		Specs:            map[string]interface{}{},
+		CompatibleCounts: map[string]int{},
+		Similar:          []models.Component{},
+	}
+	if err := json.Unmarshal(comp.Specs, &detail.Specs); err != nil || detail.Specs == nil {
+		// Зіпсовані specs не повинні ламати сторінку: показуємо компонент без характеристик
+		logging.FromContext(ctx).Warn("invalid component specs", "component_id", comp.ID, "error", err)
+		detail.Specs = map[string]interface{}{}
+	}
+
+	history, err := s.prices.GetPriceHistory(ctx, id, 30)
+	if err != nil {
+		return models.ComponentDetail{}, err
+	}
+	detail.PriceSummary = models.PriceSummary{
+		CurrentPrice: history.CurrentPrice,
+		Stats:        history.Stats,
+		IsGoodPrice:  history.IsGoodPrice,
+	}
+
+	catalog, err := s.repo.GetAll(ctx, "", 0, 0, "", "")
+	if err != nil {
+		return models.ComponentDetail{}, err
+	}
+
+	// Лише категорії, з якими є правило сумісності; інші сумісні з усім і лише роздували б лічильники
+	for _, category := range compatibilityPairs[comp.Category] {
+		detail.CompatibleCounts[category] = 0
+	}
+
+	var sameCategory []models.Component
+	for _, other := range catalog {
+		if other.ID == comp.ID {
+			continue
+		}
+		if other.Category == comp.Category {
+			sameCategory = append(sameCategory, other)
+			continue
+		}
+		if _, ok := detail.CompatibleCounts[other.Category]; !ok {
+			continue
+		}
+		// Ті самі правила, що й у перевірці збірки, для пари "цей компонент + інший"
+		if checkCompatibility([]models.Component{*comp, other}).IsValid {
+			detail.CompatibleCounts[other.Category]++
+		}
+	}
+
+	detail.Similar = similarComponents(*comp, detail.Specs, sameCategory)
+
+	return detail, nil
+}
+
+// similarComponents - спершу з тією ж ключовою характеристикою, далі - найближчі за ціною
+func similarComponents(comp models.Component, specs map[string]interface{}, candidates []models.Component) []models.Component {
+	key := similarityKeys[comp.Category]
+	want := similarityValue(specs, key)
+
+	matches := func(c models.Component) bool {
+		if want == "" {
+			return false
+		}
+		var s map[string]interface{}
+		_ = json.Unmarshal(c.Specs, &s)
+		return similarityValue(s, key) == want
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		mi, mj := matches(candidates[i]), matches(candidates[j])
+		if mi != mj {
+			return mi
+		}
+		return math.Abs(candidates[i].Price-comp.Price) < math.Abs(candidates[j].Price-comp.Price)
+	})
+
+	if len(candidates) > similarComponentsLimit {
+		candidates = candidates[:similarComponentsLimit]
+	}
+	return append([]models.Component{}, candidates...)
+}
+
+// similarityValue - рядкова характеристика у нормалізованому вигляді ("" якщо немає)
+func similarityValue(specs map[string]interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	v, _ := specs[key].(string)
+	return strings.ToUpper(strings.TrimSpace(v))
+}
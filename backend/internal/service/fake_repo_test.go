@@ -0,0 +1,221 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+)
+
+// fakeAuthRepo - repository.Authorization у пам'яті.
+// Нереалізовані методи панікують (nil-інтерфейс), тож тест одразу покаже зайвий виклик.
+type fakeAuthRepo struct {
+	repository.Authorization
+
+	nextID     int
+	users      map[int]models.User
+	identities map[string]int                  // provider + "|" + subject -> ID користувача
+	refresh    map[string]*models.RefreshToken // За хешем токена
+	twoFactor  map[int]*fakeTwoFactor
+}
+
+type fakeTwoFactor struct {
+	secret    string
+	enabledAt *time.Time
+	lastStep  int64
+	recovery  map[string]bool // хеш коду -> використано
+}
+
+func newFakeAuthRepo() *fakeAuthRepo {
+	return &fakeAuthRepo{
+		users:      map[int]models.User{},
+		identities: map[string]int{},
+		refresh:    map[string]*models.RefreshToken{},
+		twoFactor:  map[int]*fakeTwoFactor{},
+	}
+}
+
+func (r *fakeAuthRepo) addUser(u models.User) models.User {
+	r.nextID++
+	u.ID = r.nextID
+	if u.Role == "" {
+		u.Role = models.RoleCustomer
+	}
+	r.users[u.ID] = u
+	return u
+}
+
+func (r *fakeAuthRepo) GetUserByID(id int) (models.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return models.User{}, repository.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *fakeAuthRepo) GetUserByEmail(email string) (models.User, error) {
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return models.User{}, repository.ErrUserNotFound
+}
+
+func (r *fakeAuthRepo) GetUserByIdentity(provider, subject string) (models.User, error) {
+	id, ok := r.identities[provider+"|"+subject]
+	if !ok {
+		return models.User{}, repository.ErrIdentityNotFound
+	}
+	return r.GetUserByID(id)
+}
+
+func (r *fakeAuthRepo) CreateIdentity(identity models.UserIdentity) error {
+	if _, ok := r.identities[identity.Provider+"|"+identity.Subject]; ok {
+		return repository.ErrIdentityTaken
+	}
+	r.identities[identity.Provider+"|"+identity.Subject] = identity.UserID
+	return nil
+}
+
+func (r *fakeAuthRepo) CreateUserWithIdentity(user models.User, identity models.UserIdentity) (int, error) {
+	if _, err := r.GetUserByEmail(user.Email); err == nil {
+		return 0, repository.ErrEmailTaken
+	}
+	user = r.addUser(user)
+	identity.UserID = user.ID
+	return user.ID, r.CreateIdentity(identity)
+}
+
+func (r *fakeAuthRepo) CreateRefreshToken(userID int, tokenHash string, expiresAt, authTime time.Time) error {
+	rt := &models.RefreshToken{ID: int64(len(r.refresh) + 1), UserID: userID, ExpiresAt: expiresAt}
+	if !authTime.IsZero() {
+		rt.AuthTime = &authTime
+	}
+	r.refresh[tokenHash] = rt
+	return nil
+}
+
+func (r *fakeAuthRepo) GetRefreshToken(tokenHash string) (models.RefreshToken, error) {
+	rt, ok := r.refresh[tokenHash]
+	if !ok {
+		return models.RefreshToken{}, repository.ErrTokenNotFound
+	}
+	return *rt, nil
+}
+
+func (r *fakeAuthRepo) RevokeRefreshToken(tokenHash string) (bool, error) {
+	rt, ok := r.refresh[tokenHash]
+	if !ok || rt.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	rt.RevokedAt = &now
+	return true, nil
+}
+
+func (r *fakeAuthRepo) RevokeAllRefreshTokens(userID int) error {
+	now := time.Now()
+	for _, rt := range r.refresh {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			rt.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// activeSessions - невідкликані refresh-токени користувача
+func (r *fakeAuthRepo) activeSessions(userID int) int {
+	n := 0
+	for _, rt := range r.refresh {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *fakeAuthRepo) UpdatePassword(userID int, passwordHash string) error {
+	u, ok := r.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	u.Password = passwordHash
+	r.users[userID] = u
+	return nil
+}
+
+func (r *fakeAuthRepo) GetTwoFactor(userID int) (models.TwoFactor, error) {
+	tf, ok := r.twoFactor[userID]
+	if !ok {
+		return models.TwoFactor{}, nil
+	}
+	left := 0
+	for _, used := range tf.recovery {
+		if !used {
+			left++
+		}
+	}
+	return models.TwoFactor{Secret: tf.secret, EnabledAt: tf.enabledAt, RecoveryCodesLeft: left}, nil
+}
+
+func (r *fakeAuthRepo) SetPendingTOTPSecret(userID int, secret string) error {
+	r.twoFactor[userID] = &fakeTwoFactor{secret: secret}
+	return nil
+}
+
+func (r *fakeAuthRepo) EnableTwoFactor(userID int, recoveryCodeHashes []string) error {
+	now := time.Now()
+	r.twoFactor[userID].enabledAt = &now
+	return r.ReplaceRecoveryCodes(userID, recoveryCodeHashes)
+}
+
+func (r *fakeAuthRepo) ReplaceRecoveryCodes(userID int, recoveryCodeHashes []string) error {
+	tf := r.twoFactor[userID]
+	tf.recovery = map[string]bool{}
+	for _, h := range recoveryCodeHashes {
+		tf.recovery[h] = false
+	}
+	return nil
+}
+
+func (r *fakeAuthRepo) UseTOTPStep(userID int, step int64) (bool, error) {
+	tf := r.twoFactor[userID]
+	if step <= tf.lastStep {
+		return false, nil
+	}
+	tf.lastStep = step
+	return true, nil
+}
+
+func (r *fakeAuthRepo) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	tf := r.twoFactor[userID]
+	used, ok := tf.recovery[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	tf.recovery[codeHash] = true
+	return true, nil
+}
+
+// newTestKeyRing - HS256-ключ для підпису токенів у тестах
+func newTestKeyRing(t *testing.T) *KeyRing {
+	t.Helper()
+	keys, err := NewKeyRing(config.JWTConfig{
+		SigningKeyID: "test",
+		Keys:         []config.JWTKey{{ID: "test", Algorithm: "HS256", Secret: strings.Repeat("s", 32)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+// newTestAuthService - AuthService поверх repo без пошти і LoginGuard
+func newTestAuthService(t *testing.T, repo *fakeAuthRepo) *AuthService {
+	t.Helper()
+	return NewAuthService(repo, newTestKeyRing(t), nil, "http://app.test", nil, 15*time.Minute, time.Hour)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/repository"
+)
+
+// readinessTimeout - скільки чекати на БД, перш ніж вважати сервіс неготовим
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck - результат однієї перевірки готовності.
+// /readyz відкритий, тож причину збою пишемо лише в лог, а назовні віддаємо статус.
+type HealthCheck struct {
+	Status string `json:"status"` // "ok" або "fail"
+}
+
+// ReadinessReport - відповідь /readyz
+type ReadinessReport struct {
+	Ready  bool                   `json:"ready"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// HealthService - liveness/readiness для оркестратора (Render, Kubernetes ...)
+type HealthService struct {
+	repo          repository.Health
+	schemaVersion int // Остання міграція, яку знає цей бінарник
+	shuttingDown  atomic.Bool
+}
+
+func NewHealthService(repo repository.Health, schemaVersion int) *HealthService {
+	return &HealthService{repo: repo, schemaVersion: schemaVersion}
+}
+
+// SetShuttingDown - з цього моменту /readyz відповідає 503, щоб балансувальник
+// перестав слати нові запити, поки сервер дообробляє поточні
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness - чи можна зараз слати сервісу трафік: БД відповідає, схема актуальна,
+// сервер не зупиняється
+func (s *HealthService) Readiness(ctx context.Context) ReadinessReport {
+	report := ReadinessReport{Ready: true, Checks: map[string]HealthCheck{}}
+	check := func(name string, err error) {
+		if err != nil {
+			logging.FromContext(ctx).Warn("readiness check failed", "check", name, "error", err)
+			report.Ready = false
+			report.Checks[name] = HealthCheck{Status: "fail"}
+			return
+		}
+		report.Checks[name] = HealthCheck{Status: "ok"}
+	}
+
+	var shutdownErr error
+	if s.shuttingDown.Load() {
+		shutdownErr = fmt.Errorf("сервер зупиняється")
+	}
+	check("shutdown", shutdownErr)
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := s.repo.Ping(ctx); err != nil {
+		check("database", err)
+		check("migrations", fmt.Errorf("БД недоступна"))
+		return report
+	}
+	check("database", nil)
+	check("migrations", s.checkSchema(ctx))
+
+	return report
+}
+
+// checkSchema - схема має бути мігрована щонайменше до версії, яку очікує код.
+// Новішу версію приймаємо: міграції застосовуються перед розгортанням, і старі
+// інстанси мають обслуговувати запити, доки не запуститься новий.
+func (s *HealthService) checkSchema(ctx context.Context) error {
+	version, dirty, err := s.repo.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("міграція %d застосована не повністю (dirty)", version)
+	}
+	if version < s.schemaVersion {
+		return fmt.Errorf("версія схеми %d, очікується щонайменше %d (застосуйте міграції: server migrate)", version, s.schemaVersion)
+	}
+	return nil
+}
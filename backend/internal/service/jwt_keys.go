@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"pc-configurator/config"
+)
+
+// jwtKey - ключ, готовий до підпису/перевірки
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil, якщо ключ лише для перевірки
+	verifyKey interface{}
+}
+
+// KeyRing - набір ключів JWT: один активний для підпису, решта - для перевірки
+// токенів, виданих до ротації
+type KeyRing struct {
+	active  *jwtKey
+	keys    map[string]*jwtKey
+	methods []string
+}
+
+// NewKeyRing - завантажує ключі (включно з PEM-файлами) з конфігурації
+func NewKeyRing(cfg config.JWTConfig) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*jwtKey)}
+	methodSeen := map[string]bool{}
+
+	for _, kc := range cfg.Keys {
+		key, err := loadJWTKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %q: %w", kc.ID, err)
+		}
+		ring.keys[key.id] = key
+
+		if alg := key.method.Alg(); !methodSeen[alg] {
+			methodSeen[alg] = true
+			ring.methods = append(ring.methods, alg)
+		}
+	}
+
+	active, ok := ring.keys[cfg.SigningKeyID]
+	if !ok || active.signKey == nil {
+		return nil, fmt.Errorf("ключ підпису %q не знайдено або він без приватної частини", cfg.SigningKeyID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+// Sign - підписує claims активним ключем і додає заголовок kid
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	token.Header["kid"] = k.active.id
+	return token.SignedString(k.active.signKey)
+}
+
+// Parse - перевіряє підпис ключем з заголовка kid.
+// Токени без kid (видані до ротації) перевіряються ключем config.DefaultJWTKeyID.
+func (k *KeyRing) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, k.keyfunc, jwt.WithValidMethods(k.methods))
+}
+
+func (k *KeyRing) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = config.DefaultJWTKeyID
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	// Алгоритм токена має збігатися з алгоритмом ключа, інакше можлива
+	// атака з підміною алгоритму (наприклад, RS256 -> HS256 з публічним ключем як секретом)
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key.verifyKey, nil
+}
+
+func loadJWTKey(kc config.JWTKey) (*jwtKey, error) {
+	key := &jwtKey{id: kc.ID}
+
+	switch kc.Algorithm {
+	case "HS256":
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(kc.Secret)
+		key.verifyKey = []byte(kc.Secret)
+
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		if kc.PrivateKeyFile != "" {
+			pemBytes, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = priv
+			key.verifyKey = &priv.PublicKey
+		}
+		if kc.PublicKeyFile != "" {
+			pemBytes, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			if priv, ok := key.signKey.(*rsa.PrivateKey); ok && !priv.PublicKey.Equal(pub) {
+				return nil, errors.New("публічний ключ не відповідає приватному")
+			}
+			key.verifyKey = pub
+		}
+
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+		if kc.PrivateKeyFile != "" {
+			pemBytes, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			edPriv, ok := priv.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("очікується ключ Ed25519")
+			}
+			key.signKey = edPriv
+			key.verifyKey = edPriv.Public()
+		}
+		if kc.PublicKeyFile != "" {
+			pemBytes, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, err
+			}
+			if priv, ok := key.signKey.(ed25519.PrivateKey); ok && !priv.Public().(ed25519.PublicKey).Equal(pub) {
+				return nil, errors.New("публічний ключ не відповідає приватному")
+			}
+			key.verifyKey = pub
+		}
+
+	default:
+		return nil, fmt.Errorf("непідтримуваний алгоритм %q", kc.Algorithm)
+	}
+
+	if key.verifyKey == nil {
+		return nil, errors.New("не задано жодного ключа")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
+)
+
+// Політика блокування: перші FreeAttempts помилок без наслідків, далі кожна
+// наступна подвоює час блокування (BaseLockout, 2x, 4x ...), але не більше MaxLockout
+type lockoutPolicy struct {
+	FreeAttempts int
+	BaseLockout  time.Duration
+	MaxLockout   time.Duration
+}
+
+var (
+	// Підбір пароля до конкретного акаунта
+	accountLockout = lockoutPolicy{FreeAttempts: 5, BaseLockout: 30 * time.Second, MaxLockout: 15 * time.Minute}
+	// Перебір багатьох акаунтів з однієї адреси - поріг вищий через NAT/офіси
+	ipLockout = lockoutPolicy{FreeAttempts: 20, BaseLockout: 30 * time.Second, MaxLockout: time.Hour}
+)
+
+// loginAttemptsResetAfter - якщо помилок не було стільки часу, лічильник обнуляється
+const loginAttemptsResetAfter = time.Hour
+
+// LoginLockedError - вхід тимчасово заблоковано, повторити через RetryAfter
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("забагато невдалих спроб входу, спробуйте через %d с", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// LoginGuard - захист від підбору пароля: лічильники по акаунту та по IP
+type LoginGuard struct {
+	store repository.LoginAttempts
+}
+
+func NewLoginGuard(store repository.LoginAttempts) *LoginGuard {
+	return &LoginGuard{store: store}
+}
+
+// Check - повертає *LoginLockedError, якщо акаунт або IP зараз заблоковані
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	var retryAfter time.Duration
+	now := time.Now()
+
+	for _, key := range g.keys(email, ip) {
+		a, err := g.store.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return err
+		}
+		if wait := a.LockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Fail - реєструє невдалу спробу і за потреби блокує акаунт/IP
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string) error {
+	for _, key := range g.keys(email, ip) {
+		failures, err := g.store.RecordLoginFailure(ctx, key, loginAttemptsResetAfter)
+		if err != nil {
+			return err
+		}
+
+		policy := accountLockout
+		if strings.HasPrefix(key, "ip:") {
+			policy = ipLockout
+		}
+
+		if lock := policy.lockoutFor(failures); lock > 0 {
+			if err := g.store.LockLogin(ctx, key, time.Now().Add(lock)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Success - успішний вхід обнуляє лічильник акаунта. Лічильник IP не чіпаємо,
+// інакше зловмисник міг би скидати його входом у власний акаунт.
+func (g *LoginGuard) Success(ctx context.Context, email string) error {
+	return g.store.ResetLoginAttempts(ctx, "email:"+validation.NormalizeEmail(email))
+}
+
+func (g *LoginGuard) keys(email, ip string) []string {
+	keys := []string{"email:" + validation.NormalizeEmail(email)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+func (p lockoutPolicy) lockoutFor(failures int) time.Duration {
+	over := failures - p.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	// Обмежуємо степінь, щоб не переповнити Duration
+	if over > 20 {
+		return p.MaxLockout
+	}
+	lock := p.BaseLockout * time.Duration(1<<(over-1))
+	if lock > p.MaxLockout {
+		lock = p.MaxLockout
+	}
+	return lock
+}
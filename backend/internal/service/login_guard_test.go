@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pc-configurator/internal/repository"
+)
+
+func TestLockoutFor(t *testing.T) {
+	p := lockoutPolicy{FreeAttempts: 3, BaseLockout: 10 * time.Second, MaxLockout: time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{3, 0},
+		{4, 10 * time.Second},
+		{5, 20 * time.Second},
+		{6, 40 * time.Second},
+		{7, time.Minute},
+		{8, time.Minute},
+		{24, time.Minute},
+		// Степінь більше не рахується - Duration не переповнюється
+		{1000, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.lockoutFor(tt.failures); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := accountLockout.lockoutFor(accountLockout.FreeAttempts + 1); got != accountLockout.BaseLockout {
+		t.Errorf("account: first lockout = %v, want %v", got, accountLockout.BaseLockout)
+	}
+	if got := ipLockout.lockoutFor(100); got != ipLockout.MaxLockout {
+		t.Errorf("ip: lockout after 100 failures = %v, want %v", got, ipLockout.MaxLockout)
+	}
+}
+
+// lockedFor - скільки ще триває блокування; 0, якщо вхід дозволено
+func lockedFor(t *testing.T, g *LoginGuard, email, ip string) time.Duration {
+	t.Helper()
+
+	err := g.Check(context.Background(), email, ip)
+	if err == nil {
+		return 0
+	}
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Check: %v", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestLoginGuardAccount(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewLoginAttemptsMemory()
+	g := NewLoginGuard(store)
+
+	for range accountLockout.FreeAttempts {
+		if err := g.Fail(ctx, "Olena@Example.com", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if wait := lockedFor(t, g, "olena@example.com", "10.0.0.2"); wait != 0 {
+		t.Fatalf("locked after %d free attempts: %v", accountLockout.FreeAttempts, wait)
+	}
+
+	// Адреса інша, але акаунт той самий (регістр email не важить)
+	g.Fail(ctx, "olena@example.com", "10.0.0.2")
+	if wait := lockedFor(t, g, "OLENA@example.com", "10.0.0.3"); wait <= 0 || wait > accountLockout.BaseLockout {
+		t.Errorf("after the first extra failure: locked for %v, want up to %v", wait, accountLockout.BaseLockout)
+	}
+	if wait := lockedFor(t, g, "other@example.com", "10.0.0.3"); wait != 0 {
+		t.Errorf("other account is locked for %v", wait)
+	}
+
+	g.Fail(ctx, "olena@example.com", "10.0.0.2")
+	if wait := lockedFor(t, g, "olena@example.com", ""); wait <= accountLockout.BaseLockout {
+		t.Errorf("after the second extra failure: locked for %v, want doubled", wait)
+	}
+
+	// Успішний вхід обнуляє лічильник акаунта
+	if err := g.Success(ctx, "Olena@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if wait := lockedFor(t, g, "olena@example.com", ""); wait != 0 {
+		t.Errorf("locked after Success: %v", wait)
+	}
+	if a, _ := store.GetLoginAttempt(ctx, "email:olena@example.com"); a.Failures != 0 {
+		t.Errorf("account counter after Success = %d", a.Failures)
+	}
+}
+
+func TestLoginGuardIPNotResetOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewLoginAttemptsMemory()
+	g := NewLoginGuard(store)
+	const ip = "10.0.0.1"
+
+	// Перебір різних акаунтів: жоден акаунт не блокується, блокується адреса
+	for i := range ipLockout.FreeAttempts + 1 {
+		if err := g.Fail(ctx, fmt.Sprintf("user%d@example.com", i), ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if wait := lockedFor(t, g, "user0@example.com", ""); wait != 0 {
+		t.Fatalf("account locked by someone else's failures: %v", wait)
+	}
+	if wait := lockedFor(t, g, "new@example.com", ip); wait <= 0 || wait > ipLockout.BaseLockout {
+		t.Fatalf("ip locked for %v, want up to %v", wait, ipLockout.BaseLockout)
+	}
+
+	// Вхід у власний акаунт не знімає блокування з адреси
+	if err := g.Success(ctx, "user0@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if wait := lockedFor(t, g, "new@example.com", ip); wait <= 0 {
+		t.Error("Success unlocked the ip")
+	}
+	if a, _ := store.GetLoginAttempt(ctx, "ip:"+ip); a.Failures != ipLockout.FreeAttempts+1 {
+		t.Errorf("ip counter after Success = %d, want %d", a.Failures, ipLockout.FreeAttempts+1)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/logging"
+)
+
+// Mailer - відправка листів користувачам
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewMailer - обирає реалізацію за MAIL_DRIVER
+func NewMailer(cfg config.MailConfig) Mailer {
+	if cfg.Driver == "smtp" {
+		return NewSMTPMailer(cfg)
+	}
+	return NewLogMailer(cfg.LogFile)
+}
+
+// LogMailer - замість відправки пише листи в лог або у файл (для розробки й тестів)
+type LogMailer struct {
+	path string // Порожній шлях - писати в лог застосунку
+	mu   sync.Mutex
+}
+
+// NewLogMailer - якщо path порожній, листи йдуть у лог застосунку
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+func (m *LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	if m.path == "" {
+		logging.FromContext(ctx).Info("email", "to", to, "subject", subject, "body", body)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("не вдалося відкрити файл листів: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), to, subject, body)
+	return err
+}
+
+// SMTPMailer - відправка через SMTP-сервер (STARTTLS, якщо сервер його підтримує)
+type SMTPMailer struct {
+	addr string
+	host string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		host: cfg.SMTPHost,
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("некоректна адреса відправника: %w", err)
+	}
+
+	// Заборона переносів рядків у заголовках - захист від підстановки заголовків
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("некоректний заголовок листа")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + from.String() + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	// smtp.SendMail не приймає context, тому виконуємо у горутині і чекаємо ctx
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, from.Address, []string{to}, []byte(msg.String()))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("помилка відправки листа: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
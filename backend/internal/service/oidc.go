@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
+)
+
+// Скільки часу користувач має на вхід на сторінці провайдера
+const oidcStateTTL = 10 * time.Minute
+
+// oidcStateSubject - відрізняє підписаний стан входу від access-токена
+const oidcStateSubject = "oidc-state"
+
+var (
+	ErrUnknownOIDCProvider  = apperror.NotFound("unknown_oidc_provider", "невідомий провайдер входу")
+	ErrInvalidOIDCState     = apperror.Unauthorized("invalid_oidc_state", "сесія входу недійсна або протухла, спробуйте ще раз")
+	ErrOIDCEmailNotVerified = apperror.Unauthorized("oidc_email_not_verified", "провайдер не підтвердив email, вхід неможливий")
+	// ErrOIDCAccountNotVerified - акаунт з таким email є, але власник ще не підтвердив адресу
+	ErrOIDCAccountNotVerified = apperror.Conflict("oidc_account_not_verified",
+		"акаунт з цим email ще не підтверджено: увійдіть за паролем (або скиньте його) і підтвердіть email")
+)
+
+// oidcStateClaims - стан між перенаправленням до провайдера і поверненням назад.
+// Підписується ключем JWT і зберігається в cookie браузера, тож сервер нічого не пам'ятає.
+type oidcStateClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+// OIDCService - вхід через зовнішніх провайдерів (Google, Keycloak ...).
+// Видає ті ж токени, що й вхід за паролем.
+type OIDCService struct {
+	auth      *AuthService
+	repo      repository.Authorization
+	keys      *KeyRing
+	providers map[string]*OIDCProvider
+}
+
+func NewOIDCService(auth *AuthService, repo repository.Authorization, keys *KeyRing, providers []config.OIDCProvider) *OIDCService {
+	s := &OIDCService{auth: auth, repo: repo, keys: keys, providers: make(map[string]*OIDCProvider)}
+	for _, p := range providers {
+		s.providers[p.Name] = NewOIDCProvider(p)
+	}
+	return s
+}
+
+// Providers - назви налаштованих провайдерів (для кнопок входу на фронтенді)
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin - повертає адресу входу провайдера і підписаний стан для cookie
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (authURL, state string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+
+	claims := &oidcStateClaims{Provider: providerName}
+	for _, v := range []*string{&claims.State, &claims.Nonce, &claims.CodeVerifier} {
+		if *v, err = generateRandomToken(); err != nil {
+			return "", "", err
+		}
+	}
+	claims.Subject = oidcStateSubject
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(oidcStateTTL))
+
+	challenge := sha256.Sum256([]byte(claims.CodeVerifier))
+	authURL, err = provider.AuthURL(ctx, claims.State, claims.Nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", err
+	}
+
+	signedState, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, signedState, nil
+}
+
+// FinishLogin - перевіряє стан з cookie, обмінює code на ID-токен провайдера,
+// знаходить (або створює) користувача і видає пару токенів
+func (s *OIDCService) FinishLogin(ctx context.Context, providerName, signedState, state, code string) (Tokens, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return Tokens{}, ErrUnknownOIDCProvider
+	}
+
+	claims := &oidcStateClaims{}
+	if _, err := s.keys.Parse(signedState, claims); err != nil ||
+		claims.Subject != oidcStateSubject || claims.Provider != providerName ||
+		state == "" || claims.State != state {
+		return Tokens{}, ErrInvalidOIDCState
+	}
+	if code == "" {
+		return Tokens{}, errors.New("провайдер не повернув код авторизації")
+	}
+
+	identity, err := provider.Exchange(ctx, code, claims.CodeVerifier, claims.Nonce)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	user, err := s.resolveUser(identity)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return s.auth.completeLogin(user)
+}
+
+// resolveUser - користувач для зовнішнього облікового запису:
+//  1. вже прив'язаний - повертаємо його;
+//  2. є користувач з таким email - прив'язуємо, але лише якщо email підтвердили обидві сторони:
+//     провайдер (інакше будь-хто увійшов би в чужий акаунт, вказавши у провайдера чужу адресу)
+//     і наш користувач (інакше акаунт, заздалегідь зареєстрований кимось на чужу адресу,
+//     разом з паролем і сесіями зловмисника дістався б справжньому власнику адреси);
+//  3. інакше - реєструємо нового користувача (роль customer, email вже підтверджено).
+func (s *OIDCService) resolveUser(identity OIDCIdentity) (models.User, error) {
+	user, err := s.repo.GetUserByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrIdentityNotFound) {
+		return models.User{}, err
+	}
+
+	email := validation.NormalizeEmail(identity.Email)
+	if !identity.EmailVerified || validation.Email(email) != "" {
+		return models.User{}, ErrOIDCEmailNotVerified
+	}
+
+	link := models.UserIdentity{Provider: identity.Provider, Subject: identity.Subject, Email: email}
+
+	if user, err := s.repo.GetUserByEmail(email); err == nil {
+		if user.EmailVerifiedAt == nil {
+			return models.User{}, ErrOIDCAccountNotVerified
+		}
+		link.UserID = user.ID
+		if err := s.repo.CreateIdentity(link); err != nil {
+			return models.User{}, err
+		}
+		return user, nil
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
+		return models.User{}, err
+	}
+
+	// Пароля в такого користувача немає: ставимо випадковий, який ніхто не знає.
+	// Увійти за паролем можна буде після скидання пароля через email.
+	randomPassword, err := generateRandomToken()
+	if err != nil {
+		return models.User{}, err
+	}
+	hash, err := generatePasswordHash(randomPassword)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	now := time.Now()
+	user = models.User{
+		Name:            oidcDisplayName(identity.Name, email),
+		Email:           email,
+		Password:        hash,
+		Role:            models.RoleCustomer,
+		EmailVerifiedAt: &now,
+	}
+
+	id, err := s.repo.CreateUserWithIdentity(user, link)
+	if err != nil {
+		return models.User{}, fmt.Errorf("реєстрація через %s: %w", identity.Provider, err)
+	}
+	user.ID = id
+
+	return user, nil
+}
+
+// FrontendCallbackURL - сторінка фронтенду, куди повертаємо користувача після входу.
+// Токени передаються у фрагменті (#...), щоб не потрапили в логи серверів і Referer.
+func (s *OIDCService) FrontendCallbackURL(params url.Values) string {
+	return s.auth.appURL + "/auth/callback#" + params.Encode()
+}
+
+// oidcDisplayName - ім'я з профілю провайдера або, якщо його немає, частина email до "@"
+func oidcDisplayName(name, email string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	if utf8.RuneCountInString(name) > validation.MaxNameLength {
+		name = string([]rune(name)[:validation.MaxNameLength])
+	}
+	return name
+}
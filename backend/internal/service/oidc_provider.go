@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"pc-configurator/config"
+)
+
+// Не частіше ніж раз на хвилину перечитуємо JWKS, якщо прийшов токен з невідомим kid
+const oidcJWKSRefreshInterval = time.Minute
+
+// oidcDiscovery - потрібні нам поля з .well-known/openid-configuration
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCIdentity - перевірені дані користувача з ID-токена провайдера
+type OIDCIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// oidcIDTokenClaims - claims ID-токена, які ми перевіряємо або використовуємо
+type oidcIDTokenClaims struct {
+	Nonce           string      `json:"nonce"`
+	Email           string      `json:"email"`
+	EmailVerified   interface{} `json:"email_verified"` // Деякі провайдери віддають рядок "true"
+	Name            string      `json:"name"`
+	AuthorizedParty string      `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvider - клієнт одного провайдера OpenID Connect (authorization code + PKCE).
+// Discovery-документ і ключі завантажуються при першому використанні і кешуються.
+type OIDCProvider struct {
+	cfg    config.OIDCProvider
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	jwks          map[string]interface{}
+	jwksFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg config.OIDCProvider) *OIDCProvider {
+	return &OIDCProvider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// AuthURL - адреса сторінки входу провайдера, куди перенаправляємо користувача
+func (p *OIDCProvider) AuthURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("некоректний authorization_endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange - обмінює code на токени і повертає дані з перевіреного ID-токена
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (OIDCIdentity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic: id та секрет кодуються як form-значення (RFC 6749, 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := p.doJSON(req, &tokenResp); err != nil {
+		return OIDCIdentity{}, fmt.Errorf("обмін коду авторизації: %w", err)
+	}
+	if tokenResp.IDToken == "" {
+		return OIDCIdentity{}, errors.New("провайдер не повернув id_token")
+	}
+
+	return p.verifyIDToken(ctx, tokenResp.IDToken, nonce)
+}
+
+// verifyIDToken - підпис (ключем з JWKS), iss, aud, exp та nonce
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawToken, nonce string) (OIDCIdentity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+
+	claims := &oidcIDTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.getKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return OIDCIdentity{}, fmt.Errorf("недійсний id_token: %w", err)
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return OIDCIdentity{}, errors.New("недійсний id_token: nonce не збігається")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return OIDCIdentity{}, errors.New("недійсний id_token: azp не збігається")
+	}
+	if claims.Subject == "" {
+		return OIDCIdentity{}, errors.New("недійсний id_token: немає sub")
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return OIDCIdentity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var d oidcDiscovery
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery %s: %w", p.cfg.Name, err)
+	}
+	// Issuer у документі має збігатися з налаштованим (OIDC Discovery, 4.3)
+	if strings.TrimRight(d.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery %s: issuer %q не збігається з %q", p.cfg.Name, d.Issuer, p.cfg.IssuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery %s: неповний документ", p.cfg.Name)
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// getKey - ключ перевірки підпису за kid; невідомий kid означає ротацію ключів у провайдера
+func (p *OIDCProvider) getKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.jwksFetchedAt) < oidcJWKSRefreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("OIDC JWKS %s: %w", p.cfg.Name, err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.jwks = keys
+	p.jwksFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey - без kid допускаємо лише випадок, коли у провайдера один ключ
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(p.jwks) == 1 {
+			for _, key := range p.jwks {
+				return key, true
+			}
+		}
+		return nil, false
+	}
+	key, ok := p.jwks[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// jsonWebKey - публічний ключ з JWKS (RFC 7517): RSA або EC P-256
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 || exp.Int64() < 3 {
+			return nil, errors.New("некоректна RSA-експонента")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("непідтримувана крива %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("точка не на кривій")
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("непідтримуваний тип ключа %q", k.Kty)
+}
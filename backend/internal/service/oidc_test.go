@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"pc-configurator/config"
+	"pc-configurator/internal/models"
+)
+
+const (
+	testOIDCClientID = "pc-configurator"
+	testOIDCSecret   = "client-secret"
+	testOIDCKeyID    = "key-1"
+)
+
+// fakeOIDC - провайдер OpenID Connect на httptest: discovery, JWKS і token endpoint.
+// Сторінку входу імітує login: вона приймає адресу з AuthURL і повертає code.
+type fakeOIDC struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthRequest
+}
+
+// fakeAuthRequest - що провайдер запам'ятав про вхід до обміну code на токени
+type fakeAuthRequest struct {
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims // sub, email, email_verified ... для ID-токена
+}
+
+func newFakeOIDC(t *testing.T) *fakeOIDC {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeOIDC{key: key, codes: map[string]fakeAuthRequest{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.srv.URL,
+			"authorization_endpoint": p.srv.URL + "/authorize",
+			"token_endpoint":         p.srv.URL + "/token",
+			"jwks_uri":               p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": testOIDCKeyID,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("POST /token", p.token)
+
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// login - користувач увійшов на сторінці провайдера; повертає code для callback
+func (p *fakeOIDC) login(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testOIDCClientID || q.Get("response_type") != "code" {
+		t.Fatalf("unexpected auth request: %s", authURL)
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" || q.Get("nonce") == "" {
+		t.Fatalf("auth request without PKCE or nonce: %s", authURL)
+	}
+
+	code, err := generateRandomToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = fakeAuthRequest{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+	return code
+}
+
+func (p *fakeOIDC) token(w http.ResponseWriter, r *http.Request) {
+	tokenError := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+
+	if id, secret, ok := r.BasicAuth(); !ok || id != testOIDCClientID || secret != testOIDCSecret {
+		tokenError("invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	req, ok := p.codes[r.FormValue("code")]
+	delete(p.codes, r.FormValue("code")) // Code одноразовий
+	p.mu.Unlock()
+	if !ok {
+		tokenError("invalid_grant")
+		return
+	}
+
+	// PKCE (RFC 7636): code обмінюється лише разом з verifier того, хто почав вхід
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		tokenError("invalid_grant")
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.srv.URL,
+		"aud":   testOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": req.nonce,
+	}
+	for k, v := range req.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testOIDCKeyID
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "opaque", "token_type": "Bearer", "id_token": idToken})
+}
+
+// newTestOIDCService - OIDCService з одним провайдером "test", що веде на fakeOIDC
+func newTestOIDCService(t *testing.T, repo *fakeAuthRepo, provider *fakeOIDC) (*OIDCService, *AuthService) {
+	t.Helper()
+
+	auth := newTestAuthService(t, repo)
+	svc := NewOIDCService(auth, repo, auth.keys, []config.OIDCProvider{{
+		Name:         "test",
+		IssuerURL:    provider.srv.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCSecret,
+		RedirectURL:  "http://api.test/api/auth/oidc/test/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	}})
+	return svc, auth
+}
+
+// startOIDCLogin - StartLogin і state з адреси провайдера (те, що повернеться в callback)
+func startOIDCLogin(t *testing.T, svc *OIDCService) (authURL, signedState, state string) {
+	t.Helper()
+
+	authURL, signedState, err := svc.StartLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	return authURL, signedState, u.Query().Get("state")
+}
+
+func verifiedClaims(sub, email string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "email": email, "email_verified": true, "name": "Олена"}
+}
+
+func TestOIDCLoginRegistersNewUser(t *testing.T) {
+	repo := newFakeAuthRepo()
+	provider := newFakeOIDC(t)
+	svc, auth := newTestOIDCService(t, repo, provider)
+
+	authURL, signedState, state := startOIDCLogin(t, svc)
+	code := provider.login(t, authURL, verifiedClaims("sub-1", "Olena@Example.com"))
+
+	tokens, err := svc.FinishLogin(context.Background(), "test", signedState, state, code)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+
+	userID, role, err := auth.ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	user := repo.users[userID]
+	if user.Email != "olena@example.com" || user.Name != "Олена" || role != models.RoleCustomer {
+		t.Errorf("user = %+v, role %q", user, role)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("email of a new OIDC user must be verified")
+	}
+	if repo.identities["test|sub-1"] != userID {
+		t.Error("identity is not linked to the new user")
+	}
+
+	// Повторний вхід знаходить користувача за sub, навіть якщо email у провайдера змінився
+	authURL, signedState, state = startOIDCLogin(t, svc)
+	code = provider.login(t, authURL, verifiedClaims("sub-1", "new@example.com"))
+	tokens, err = svc.FinishLogin(context.Background(), "test", signedState, state, code)
+	if err != nil {
+		t.Fatalf("second FinishLogin: %v", err)
+	}
+	if id, _, _ := auth.ParseToken(tokens.AccessToken); id != userID || len(repo.users) != 1 {
+		t.Errorf("second login: user %d, %d users in repo", id, len(repo.users))
+	}
+}
+
+func TestOIDCLoginRejectsInvalidState(t *testing.T) {
+	repo := newFakeAuthRepo()
+	provider := newFakeOIDC(t)
+	svc, _ := newTestOIDCService(t, repo, provider)
+
+	authURL, signedState, state := startOIDCLogin(t, svc)
+	code := provider.login(t, authURL, verifiedClaims("sub-1", "olena@example.com"))
+
+	// Стан, підписаний чужим ключем
+	foreign := NewOIDCService(nil, repo, newForeignKeyRing(t), nil)
+	foreign.providers = svc.providers
+	_, foreignState, err := foreign.StartLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		signedState string
+		state       string
+	}{
+		{"no cookie", "", state},
+		{"no state in callback", signedState, ""},
+		{"state mismatch", signedState, state + "x"},
+		{"tampered cookie", signedState[:len(signedState)-2] + "xx", state},
+		{"cookie signed by another key", foreignState, state},
+		{"access token instead of state", mustSignAccessToken(t, svc.keys), state},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.FinishLogin(context.Background(), "test", tt.signedState, tt.state, code)
+			if !errors.Is(err, ErrInvalidOIDCState) {
+				t.Errorf("err = %v, want ErrInvalidOIDCState", err)
+			}
+		})
+	}
+
+	if len(repo.users) != 0 {
+		t.Errorf("users created: %d", len(repo.users))
+	}
+}
+
+func TestOIDCLoginRejectsCodeWithoutMatchingVerifier(t *testing.T) {
+	repo := newFakeAuthRepo()
+	provider := newFakeOIDC(t)
+	svc, _ := newTestOIDCService(t, repo, provider)
+
+	// Перехоплений code жертви, підставлений у власний вхід зловмисника:
+	// state збігається, але code_verifier не відповідає code_challenge жертви
+	victimURL, _, _ := startOIDCLogin(t, svc)
+	victimCode := provider.login(t, victimURL, verifiedClaims("victim", "victim@example.com"))
+
+	_, attackerState, state := startOIDCLogin(t, svc)
+	if _, err := svc.FinishLogin(context.Background(), "test", attackerState, state, victimCode); err == nil {
+		t.Fatal("code accepted with another login's code_verifier")
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("users created: %d", len(repo.users))
+	}
+}
+
+func TestOIDCLoginRejectsNonceMismatch(t *testing.T) {
+	repo := newFakeAuthRepo()
+	provider := newFakeOIDC(t)
+	svc, _ := newTestOIDCService(t, repo, provider)
+
+	for name, nonce := range map[string]interface{}{"other nonce": "replayed-nonce", "no nonce": ""} {
+		t.Run(name, func(t *testing.T) {
+			authURL, signedState, state := startOIDCLogin(t, svc)
+			claims := verifiedClaims("sub-1", "olena@example.com")
+			claims["nonce"] = nonce
+			code := provider.login(t, authURL, claims)
+
+			_, err := svc.FinishLogin(context.Background(), "test", signedState, state, code)
+			if err == nil || !strings.Contains(err.Error(), "nonce") {
+				t.Errorf("err = %v, want nonce error", err)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginEmailVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified interface{}
+		wantErr       error
+	}{
+		{"verified bool", true, nil},
+		{"verified string", "true", nil},
+		{"not verified", false, ErrOIDCEmailNotVerified},
+		{"not verified string", "false", ErrOIDCEmailNotVerified},
+		{"claim missing", nil, ErrOIDCEmailNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepo()
+			provider := newFakeOIDC(t)
+			svc, _ := newTestOIDCService(t, repo, provider)
+
+			claims := jwt.MapClaims{"sub": "sub-1", "email": "olena@example.com"}
+			if tt.emailVerified != nil {
+				claims["email_verified"] = tt.emailVerified
+			}
+			authURL, signedState, state := startOIDCLogin(t, svc)
+			code := provider.login(t, authURL, claims)
+
+			_, err := svc.FinishLogin(context.Background(), "test", signedState, state, code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(repo.users)+len(repo.identities) != 0 {
+				t.Error("user or identity created for an unverified email")
+			}
+		})
+	}
+}
+
+func TestOIDCLoginLinksExistingAccount(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		verifiedAt *time.Time
+		wantErr    error
+	}{
+		{"verified local account is linked", &verifiedAt, nil},
+		{"unverified local account is not linked", nil, ErrOIDCAccountNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepo()
+			provider := newFakeOIDC(t)
+			svc, auth := newTestOIDCService(t, repo, provider)
+
+			local := repo.addUser(models.User{Name: "Олена", Email: "olena@example.com", Password: "hash", EmailVerifiedAt: tt.verifiedAt})
+
+			authURL, signedState, state := startOIDCLogin(t, svc)
+			code := provider.login(t, authURL, verifiedClaims("sub-1", "OLENA@example.com"))
+			tokens, err := svc.FinishLogin(context.Background(), "test", signedState, state, code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if len(repo.identities) != 0 || repo.users[local.ID].EmailVerifiedAt != nil {
+					t.Error("unverified account was linked or marked verified")
+				}
+				return
+			}
+
+			if id, _, _ := auth.ParseToken(tokens.AccessToken); id != local.ID {
+				t.Errorf("logged in as %d, want existing user %d", id, local.ID)
+			}
+			if repo.identities["test|sub-1"] != local.ID || len(repo.users) != 1 {
+				t.Error("identity is not linked to the existing user")
+			}
+		})
+	}
+}
+
+// newForeignKeyRing - ключ, яким сервер не підписує
+func newForeignKeyRing(t *testing.T) *KeyRing {
+	t.Helper()
+	keys, err := NewKeyRing(config.JWTConfig{
+		SigningKeyID: "test",
+		Keys:         []config.JWTKey{{ID: "test", Algorithm: "HS256", Secret: strings.Repeat("f", 32)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func mustSignAccessToken(t *testing.T, keys *KeyRing) string {
+	t.Helper()
+	token, err := keys.Sign(&tokenClaims{
+		UserID:           1,
+		Role:             models.RoleCustomer,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+)
+
+// Періоди, за які рахуємо статистику цін (днів)
+var priceStatsPeriods = []int{30, 90}
+
+// MaxPriceHistoryDays - найдовший період, який можна запросити через API
+const MaxPriceHistoryDays = 365
+
+type PriceHistoryService struct {
+	compRepo    repository.ComponentRepository
+	historyRepo repository.PriceHistory
+}
+
+func NewPriceHistoryService(compRepo repository.ComponentRepository, historyRepo repository.PriceHistory) *PriceHistoryService {
+	return &PriceHistoryService{compRepo: compRepo, historyRepo: historyRepo}
+}
+
+// GetPriceHistory - історія цін за days днів і статистика за 30/90 днів
+func (s *PriceHistoryService) GetPriceHistory(ctx context.Context, componentID int, days int) (models.PriceHistory, error) {
+	result := models.PriceHistory{ComponentID: componentID}
+
+	comp, err := s.compRepo.GetByID(ctx, componentID)
+	if err != nil {
+		return result, err
+	}
+	result.CurrentPrice = comp.Price
+
+	// Для статистики потрібен щонайменше найдовший період
+	window := days
+	for _, p := range priceStatsPeriods {
+		if p > window {
+			window = p
+		}
+	}
+
+	now := time.Now()
+	points, err := s.historyRepo.GetPriceHistory(ctx, componentID, now.AddDate(0, 0, -window))
+	if err != nil {
+		return result, err
+	}
+
+	for _, period := range priceStatsPeriods {
+		result.Stats = append(result.Stats, priceStats(points, comp.Price, period, now))
+	}
+
+	// Віддаємо лише запитаний період (плюс ціну, що діяла на його початок)
+	result.History = pointsSince(points, now.AddDate(0, 0, -days))
+
+	if len(result.Stats) > 0 {
+		longest := result.Stats[len(result.Stats)-1]
+		result.IsGoodPrice = comp.Price <= longest.Avg
+	}
+
+	return result, nil
+}
+
+// pointsSince - точки після since і одна остання перед ним
+func pointsSince(points []models.PricePoint, since time.Time) []models.PricePoint {
+	start := 0
+	for i, p := range points {
+		if p.ChangedAt.Before(since) {
+			start = i
+		}
+	}
+	return points[start:]
+}
+
+// priceStats - min/avg/max за період з урахуванням ціни, що діяла на його початок.
+// Avg зважене за часом: кожна ціна важить стільки, скільки вона діяла в межах періоду,
+// тож короткочасна знижка не тягне середнє так само, як ціна, що трималася місяць.
+// Якщо історії немає (компонент старший за таблицю), беремо поточну ціну.
+func priceStats(points []models.PricePoint, current float64, days int, now time.Time) models.PriceStats {
+	stats := models.PriceStats{Days: days, Min: current, Max: current, Avg: current}
+
+	since := now.AddDate(0, 0, -days)
+	points = pointsSince(points, since)
+	if len(points) == 0 {
+		return stats
+	}
+
+	var weighted, total float64
+	for i, p := range points {
+		stats.Min = math.Min(stats.Min, p.Price)
+		stats.Max = math.Max(stats.Max, p.Price)
+
+		// Ціна діє від зміни (але не раніше початку періоду) до наступної зміни або до now
+		from, to := p.ChangedAt, now
+		if from.Before(since) {
+			from = since
+		}
+		if i+1 < len(points) {
+			to = points[i+1].ChangedAt
+		}
+		if d := to.Sub(from).Seconds(); d > 0 {
+			weighted += p.Price * d
+			total += d
+		}
+	}
+
+	if total > 0 {
+		stats.Avg = math.Round(weighted/total*100) / 100
+	}
+
+	return stats
+}
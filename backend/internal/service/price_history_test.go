@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+func TestPriceStats(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+
+	tests := []struct {
+		name    string
+		points  []models.PricePoint
+		current float64
+		days    int
+		want    models.PriceStats
+	}{
+		{
+			name:    "no history",
+			current: 1000,
+			days:    30,
+			want:    models.PriceStats{Days: 30, Min: 1000, Avg: 1000, Max: 1000},
+		},
+		{
+			name:    "one price set before the window",
+			points:  []models.PricePoint{{Price: 900, ChangedAt: daysAgo(120)}},
+			current: 900,
+			days:    30,
+			want:    models.PriceStats{Days: 30, Min: 900, Avg: 900, Max: 900},
+		},
+		{
+			name:    "one price set inside the window",
+			points:  []models.PricePoint{{Price: 900, ChangedAt: daysAgo(10)}},
+			current: 900,
+			days:    30,
+			want:    models.PriceStats{Days: 30, Min: 900, Avg: 900, Max: 900},
+		},
+		{
+			// Ціна з початку періоду діяла половину часу, нова - другу половину
+			name: "change in the middle of the window",
+			points: []models.PricePoint{
+				{Price: 1000, ChangedAt: daysAgo(60)},
+				{Price: 800, ChangedAt: daysAgo(15)},
+			},
+			current: 800,
+			days:    30,
+			want:    models.PriceStats{Days: 30, Min: 800, Avg: 900, Max: 1000},
+		},
+		{
+			// Період довший за історію: рахуємо лише час, за який ціни відомі (45 днів по 1000, 15 по 800)
+			name: "window longer than the history",
+			points: []models.PricePoint{
+				{Price: 1000, ChangedAt: daysAgo(60)},
+				{Price: 800, ChangedAt: daysAgo(15)},
+			},
+			current: 800,
+			days:    90,
+			want:    models.PriceStats{Days: 90, Min: 800, Avg: 950, Max: 1000},
+		},
+		{
+			// Одноденна знижка майже не зсуває середнє
+			name: "short discount",
+			points: []models.PricePoint{
+				{Price: 1000, ChangedAt: daysAgo(100)},
+				{Price: 500, ChangedAt: daysAgo(3)},
+				{Price: 1000, ChangedAt: daysAgo(2)},
+			},
+			current: 1000,
+			days:    30,
+			want:    models.PriceStats{Days: 30, Min: 500, Avg: 983.33, Max: 1000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceStats(tt.points, tt.current, tt.days, now); got != tt.want {
+				t.Errorf("priceStats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
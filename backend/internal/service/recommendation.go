@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"time"
 
+	"pc-configurator/internal/metrics"
 	"pc-configurator/internal/models"
 	"pc-configurator/internal/repository"
 )
@@ -53,6 +55,9 @@ func NewRecommendationService(repo repository.ComponentRepository) *Recommendati
 
 // GetRecommendation - основна функція підбору
 func (s *RecommendationService) GetRecommendation(ctx context.Context, req RecommendationRequest) (RecommendationResult, error) {
+	start := time.Now()
+	defer func() { metrics.RecommendationDuration.Observe(time.Since(start).Seconds()) }()
+
 	result := RecommendationResult{}
 
 	// Отримуємо всі компоненти - передаємо параметри для фільтрації
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"pc-configurator/internal/apperror"
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+)
+
+// Параметри TOTP (RFC 6238) - ті, що підтримують усі застосунки-автентифікатори
+const (
+	totpIssuer = "PC Configurator"
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+	// totpSkew - приймаємо також код сусіднього інтервалу (розбіжність годинників)
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+
+	// twoFactorChallengeTTL - скільки часу є на введення коду після пароля
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorChallengeSubject - відрізняє challenge-токен від access-токена
+	twoFactorChallengeSubject = "2fa-challenge"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = apperror.Conflict("two_factor_already_enabled", "двофакторну автентифікацію вже увімкнено")
+	ErrTwoFactorNotEnabled     = apperror.Conflict("two_factor_not_enabled", "двофакторну автентифікацію не увімкнено")
+	ErrInvalidTwoFactorCode    = apperror.Unauthorized("invalid_two_factor_code", "невірний код підтвердження")
+	ErrInvalidChallengeToken   = apperror.Unauthorized("invalid_challenge_token", "сесія входу недійсна або протухла, увійдіть знову")
+)
+
+// TOTPEnrollment - дані для застосунку-автентифікатора (QR-код будує фронтенд з URI)
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// twoFactorChallengeClaims - підтверджує, що пароль уже перевірено.
+// UserID лежить в окремому claim, щоб цей токен не приймався як access-токен.
+type twoFactorChallengeClaims struct {
+	ChallengeUserID int `json:"challenge_uid"`
+	jwt.RegisteredClaims
+}
+
+// completeLogin - другий крок входу (за паролем або через OIDC): якщо 2FA увімкнено,
+// замість токенів видаємо challenge-токен, який обмінюється на токени в VerifyTwoFactor
+func (s *AuthService) completeLogin(user models.User) (Tokens, error) {
+	tf, err := s.repo.GetTwoFactor(user.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !tf.Enabled() {
+		return s.issueTokens(user, time.Now())
+	}
+
+	challenge, err := s.keys.Sign(&twoFactorChallengeClaims{
+		ChallengeUserID: user.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   twoFactorChallengeSubject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(twoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// VerifyTwoFactor - обмінює challenge-токен і код (TOTP або код відновлення) на пару токенів.
+// Невдалі спроби рахуються тим самим LoginGuard, що й невірні паролі.
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, challengeToken, code, clientIP string) (Tokens, error) {
+	claims := &twoFactorChallengeClaims{}
+	if _, err := s.keys.Parse(challengeToken, claims); err != nil ||
+		claims.Subject != twoFactorChallengeSubject || claims.ChallengeUserID <= 0 {
+		return Tokens{}, ErrInvalidChallengeToken
+	}
+
+	user, err := s.repo.GetUserByID(claims.ChallengeUserID)
+	if err != nil {
+		return Tokens{}, ErrInvalidChallengeToken
+	}
+
+	if err := s.guard.Check(ctx, user.Email, clientIP); err != nil {
+		return Tokens{}, err
+	}
+
+	if err := s.checkSecondFactor(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := s.guard.Fail(ctx, user.Email, clientIP); err != nil {
+				logging.FromContext(ctx).Warn("login guard update failed", "error", err)
+			}
+		}
+		return Tokens{}, err
+	}
+
+	if err := s.guard.Success(ctx, user.Email); err != nil {
+		logging.FromContext(ctx).Warn("login guard reset failed", "user_id", user.ID, "error", err)
+	}
+
+	return s.issueTokens(user, time.Now())
+}
+
+// GetTwoFactorStatus - чи увімкнено 2FA і скільки лишилось кодів відновлення
+func (s *AuthService) GetTwoFactorStatus(userID int) (models.TwoFactor, error) {
+	return s.repo.GetTwoFactor(userID)
+}
+
+// EnrollTOTP - генерує новий секрет; 2FA почне діяти після ConfirmTOTP
+func (s *AuthService) EnrollTOTP(userID int) (TOTPEnrollment, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	tf, err := s.repo.GetTwoFactor(userID)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if tf.Enabled() {
+		return TOTPEnrollment{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if err := s.repo.SetPendingTOTPSecret(userID, secret); err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	return TOTPEnrollment{Secret: secret, URI: totpURI(secret, user.Email)}, nil
+}
+
+// ConfirmTOTP - перший код з автентифікатора вмикає 2FA; повертає коди відновлення
+// (показуються користувачу один раз, у БД лише хеші)
+func (s *AuthService) ConfirmTOTP(userID int, code string) ([]string, error) {
+	tf, err := s.repo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if tf.Secret == "" {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	step, ok := verifyTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	// Код цього інтервалу вже використано (напр. перехоплений) - вдруге не приймаємо
+	fresh, err := s.repo.UseTOTPStep(userID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTwoFactor(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor - потребує і пароль, і чинний код: викраденого access-токена недостатньо
+func (s *AuthService) DisableTwoFactor(userID int, password, code string) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+	if err := s.checkSecondFactor(userID, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTwoFactor(userID)
+}
+
+// RegenerateRecoveryCodes - нові коди відновлення замість старих (потрібен чинний код)
+func (s *AuthService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.checkSecondFactor(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// checkSecondFactor - код з автентифікатора (кожен інтервал - один раз) або код відновлення
+func (s *AuthService) checkSecondFactor(userID int, code string) error {
+	tf, err := s.repo.GetTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := verifyTOTP(tf.Secret, code, time.Now()); ok {
+		fresh, err := s.repo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.ConsumeRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateTOTPSecret - 160 біт (рекомендовано RFC 4226) у base32 без "="
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// totpURI - формат Key URI, який розуміють Google Authenticator, Authy тощо
+func totpURI(secret, account string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", totpIssuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// verifyTOTP - повертає інтервал, якому відповідає код (для захисту від повторного використання)
+func verifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode - HOTP (RFC 4226) для номера інтервалу
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
+
+// generateRecoveryCodes - коди вигляду "abcd-efgh-ijkl" і їх хеші для БД
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 8) // 64 біти -> 13 символів base32, беремо 12
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:12]
+		code := raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode - код можна ввести з дефісами чи без, у будь-якому регістрі
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pc-configurator/internal/models"
+)
+
+// Тестові вектори RFC 6238, додаток B (SHA1, ключ "12345678901234567890").
+// У RFC коди 8-значні, ми видаємо 6 - це останні 6 цифр того самого числа.
+var totpRFCVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+const totpRFCKey = "12345678901234567890"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, v := range totpRFCVectors {
+		step := v.unix / int64(totpPeriod.Seconds())
+		if got := totpCode([]byte(totpRFCKey), step); got != v.code {
+			t.Errorf("T=%d: code %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(totpRFCKey))
+
+	for _, v := range totpRFCVectors {
+		now := time.Unix(v.unix, 0)
+		wantStep := v.unix / int64(totpPeriod.Seconds())
+
+		// Секрет у нижньому регістрі - так його інколи вводять вручну
+		for _, s := range []string{secret, strings.ToLower(secret)} {
+			step, ok := verifyTOTP(s, v.code, now)
+			if !ok || step != wantStep {
+				t.Errorf("T=%d: verifyTOTP = (%d, %v), want (%d, true)", v.unix, step, ok, wantStep)
+			}
+		}
+	}
+
+	key := []byte(totpRFCKey)
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / int64(totpPeriod.Seconds())
+
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"previous step", totpCode(key, current-1), true},
+		{"next step", totpCode(key, current+1), true},
+		{"two steps ago", totpCode(key, current-2), false},
+		{"two steps ahead", totpCode(key, current+2), false},
+		{"too short", totpCode(key, current)[:5], false},
+		{"not digits", "abcdef", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := verifyTOTP(secret, tt.code, now); ok != tt.want {
+				t.Errorf("verifyTOTP(%q) = %v, want %v", tt.code, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := verifyTOTP("not base32!", totpCode(key, current), now); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+// currentTOTP - чинний код для base32-секрету
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, time.Now().Unix()/int64(totpPeriod.Seconds()))
+}
+
+// enrollTestUser - користувач з секретом TOTP, який ще не підтверджено
+func enrollTestUser(t *testing.T) (*AuthService, *fakeAuthRepo, models.User, TOTPEnrollment) {
+	t.Helper()
+
+	repo := newFakeAuthRepo()
+	auth := newTestAuthService(t, repo)
+	user := repo.addUser(models.User{Name: "Олена", Email: "olena@example.com"})
+
+	enrollment, err := auth.EnrollTOTP(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth, repo, user, enrollment
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	auth, repo, user, enrollment := enrollTestUser(t)
+
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Errorf("URI = %q", enrollment.URI)
+	}
+
+	if _, err := auth.ConfirmTOTP(user.ID, "12345"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("wrong code: err = %v", err)
+	}
+
+	code := currentTOTP(t, enrollment.Secret)
+	codes, err := auth.ConfirmTOTP(user.ID, code)
+	if err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("%d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	if tf, _ := repo.GetTwoFactor(user.ID); !tf.Enabled() || tf.RecoveryCodesLeft != recoveryCodeCount {
+		t.Errorf("two factor state after confirm: %+v", tf)
+	}
+
+	// Код, яким підтвердили підключення, не можна використати ще раз для входу
+	if err := auth.checkSecondFactor(user.ID, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("replayed confirmation code: err = %v", err)
+	}
+
+	if _, err := auth.ConfirmTOTP(user.ID, code); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Errorf("second confirm: err = %v", err)
+	}
+}
+
+func TestConfirmTOTPRejectsUsedStep(t *testing.T) {
+	auth, repo, user, enrollment := enrollTestUser(t)
+
+	// Код цього інтервалу вже хтось використав - підключення ним не підтверджується
+	code := currentTOTP(t, enrollment.Secret)
+	step, ok := verifyTOTP(enrollment.Secret, code, time.Now())
+	if !ok {
+		t.Fatal("current code is not valid")
+	}
+	repo.twoFactor[user.ID].lastStep = step
+
+	if _, err := auth.ConfirmTOTP(user.ID, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if tf, _ := repo.GetTwoFactor(user.ID); tf.Enabled() {
+		t.Error("two factor enabled with a replayed code")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	auth, repo, user, enrollment := enrollTestUser(t)
+
+	codes, err := auth.ConfirmTOTP(user.ID, currentTOTP(t, enrollment.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, c := range codes {
+		if len(c) != 14 || c[4] != '-' || c[9] != '-' || seen[c] {
+			t.Errorf("bad or duplicate recovery code %q", c)
+		}
+		seen[c] = true
+	}
+
+	// Код можна ввести без дефісів, у верхньому регістрі і з пробілами навколо
+	typed := " " + strings.ToUpper(strings.ReplaceAll(codes[0], "-", "")) + " "
+	if err := auth.checkSecondFactor(user.ID, typed); err != nil {
+		t.Fatalf("recovery code rejected: %v", err)
+	}
+	if tf, _ := repo.GetTwoFactor(user.ID); tf.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("codes left = %d, want %d", tf.RecoveryCodesLeft, recoveryCodeCount-1)
+	}
+
+	// Кожен код - одноразовий
+	if err := auth.checkSecondFactor(user.ID, codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("used recovery code: err = %v", err)
+	}
+	if err := auth.checkSecondFactor(user.ID, "aaaa-bbbb-cccc"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("unknown recovery code: err = %v", err)
+	}
+
+	// Після перевипуску старі коди недійсні
+	fresh, err := auth.RegenerateRecoveryCodes(user.ID, codes[1])
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	if err := auth.checkSecondFactor(user.ID, codes[2]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("old recovery code after regeneration: err = %v", err)
+	}
+	if err := auth.checkSecondFactor(user.ID, fresh[0]); err != nil {
+		t.Errorf("new recovery code rejected: %v", err)
+	}
+}
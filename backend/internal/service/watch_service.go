@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pc-configurator/internal/logging"
+	"pc-configurator/internal/models"
+	"pc-configurator/internal/repository"
+	"pc-configurator/internal/validation"
+)
+
+// Скільки сповіщень віддаємо у профілі
+const notificationsLimit = 50
+
+// WatchService - відстеження цін користувачами
+type WatchService struct {
+	repo     repository.Watchlist
+	compRepo repository.ComponentRepository
+}
+
+func NewWatchService(repo repository.Watchlist, compRepo repository.ComponentRepository) *WatchService {
+	return &WatchService{repo: repo, compRepo: compRepo}
+}
+
+// CreateWatch - перевіряє, що компоненти існують, і створює відстеження
+func (s *WatchService) CreateWatch(ctx context.Context, w models.Watch) (int, error) {
+	errs := validation.Errors{}
+	if w.TargetPrice <= 0 {
+		errs.Add("target_price", "цільова ціна має бути більшою за 0")
+	}
+	if len(w.ComponentIDs) == 0 {
+		errs.Add("component_ids", "потрібно вказати хоча б один компонент")
+	}
+
+	switch w.Kind {
+	case "":
+		w.Kind = models.WatchKindBuild
+		if len(w.ComponentIDs) == 1 {
+			w.Kind = models.WatchKindComponent
+		}
+	case models.WatchKindComponent:
+		if len(w.ComponentIDs) != 1 {
+			errs.Add("component_ids", "для відстеження компонента потрібен рівно один component_id")
+		}
+	case models.WatchKindBuild:
+	default:
+		errs.Add("kind", fmt.Sprintf("невідомий тип відстеження %q", w.Kind))
+	}
+	if err := errs.Err(); err != nil {
+		return 0, err
+	}
+
+	components, err := s.compRepo.GetByIDs(ctx, w.ComponentIDs)
+	if err != nil {
+		return 0, err
+	}
+	var names []string
+	for _, comp := range components {
+		names = append(names, comp.Name)
+	}
+
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		w.Name = strings.Join(names, " + ")
+	}
+
+	return s.repo.CreateWatch(ctx, w)
+}
+
+func (s *WatchService) GetUserWatches(ctx context.Context, userID int) ([]models.Watch, error) {
+	return s.repo.GetUserWatches(ctx, userID)
+}
+
+func (s *WatchService) DeleteWatch(ctx context.Context, userID, watchID int) error {
+	return s.repo.DeleteWatch(ctx, userID, watchID)
+}
+
+func (s *WatchService) GetNotifications(ctx context.Context, userID int) ([]models.Notification, error) {
+	return s.repo.GetUserNotifications(ctx, userID, notificationsLimit)
+}
+
+func (s *WatchService) MarkNotificationsRead(ctx context.Context, userID int, id int64) error {
+	return s.repo.MarkNotificationsRead(ctx, userID, id)
+}
+
+// PriceAlertWorker - фонова перевірка цін у процесі сервера.
+// Повідомляє один раз, коли ціна опускається до цільової, і знову -
+// лише якщо ціна впала ще нижче або спершу піднялася вище цілі.
+type PriceAlertWorker struct {
+	repo     repository.Watchlist
+	mailer   Mailer
+	interval time.Duration
+}
+
+func NewPriceAlertWorker(repo repository.Watchlist, mailer Mailer, interval time.Duration) *PriceAlertWorker {
+	return &PriceAlertWorker{repo: repo, mailer: mailer, interval: interval}
+}
+
+// Run - перевіряє ціни кожні interval, доки не скасовано ctx
+func (w *PriceAlertWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.CheckOnce(ctx); err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error("price alert check failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce - один прохід по всіх відстеженнях
+func (w *PriceAlertWorker) CheckOnce(ctx context.Context) error {
+	watches, err := w.repo.GetAllWatches(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, watch := range watches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := w.checkWatch(ctx, watch); err != nil {
+			logging.FromContext(ctx).Error("price alert check failed", "watch_id", watch.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *PriceAlertWorker) checkWatch(ctx context.Context, watch models.Watch) error {
+	if watch.CurrentPrice == nil {
+		return nil
+	}
+	current := *watch.CurrentPrice
+
+	// Ціна знову вища за ціль - скидаємо, щоб повідомити при наступному падінні
+	if current > watch.TargetPrice {
+		if watch.LastNotifiedPrice != nil {
+			return w.repo.SetLastNotifiedPrice(ctx, watch.ID, nil)
+		}
+		return nil
+	}
+
+	if watch.LastNotifiedPrice != nil && current >= *watch.LastNotifiedPrice {
+		return nil
+	}
+
+	title := fmt.Sprintf("Ціна знизилась: %s", watch.Name)
+	message := fmt.Sprintf("Поточна ціна %.2f грн не перевищує вашу цільову %.2f грн.", current, watch.TargetPrice)
+	if watch.Kind == models.WatchKindBuild {
+		message = fmt.Sprintf("Ваша збірка тепер коштує %.2f грн і вкладається в бюджет %.2f грн.", current, watch.TargetPrice)
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"watch_id":      watch.ID,
+		"component_ids": watch.ComponentIDs,
+		"price":         current,
+		"target_price":  watch.TargetPrice,
+	})
+
+	created, err := w.repo.NotifyPriceDrop(ctx, watch.ID, watch.LastNotifiedPrice, current, models.Notification{
+		UserID:  watch.UserID,
+		Type:    models.NotificationPriceDrop,
+		Title:   title,
+		Message: message,
+		Data:    data,
+	})
+	if err != nil || !created {
+		// !created - відстеження змінилося після читання (інший інстанс уже повідомив)
+		return err
+	}
+
+	// Лист - додатковий канал: помилка відправки не скасовує внутрішнє сповіщення
+	if w.mailer != nil && watch.UserEmail != "" {
+		if err := w.mailer.Send(ctx, watch.UserEmail, title, message); err != nil {
+			logging.FromContext(ctx).Warn("price alert email failed", "user_id", watch.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package validation - перевірка вхідних даних з помилками по кожному полю.
+// Хендлери віддають їх клієнту як {"errors": {"email": "..."}}.
+package validation
+
+import (
+	"net/mail"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pc-configurator/internal/apperror"
+)
+
+// Errors - помилки валідації: поле -> опис проблеми
+type Errors map[string]string
+
+// Add - додає помилку поля (перша помилка поля не перезаписується)
+func (e Errors) Add(field, message string) {
+	if _, exists := e[field]; !exists {
+		e[field] = message
+	}
+}
+
+// Check - додає помилку, якщо message не порожній (зручно з функціями нижче)
+func (e Errors) Check(field, message string) {
+	if message != "" {
+		e.Add(field, message)
+	}
+}
+
+// Err - nil, якщо помилок немає (щоб не повертати непорожній інтерфейс з порожньою мапою)
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f+": "+e[f])
+	}
+	return "некоректні дані: " + strings.Join(parts, "; ")
+}
+
+// Is - помилки валідації належать до виду apperror.ErrValidation
+func (e Errors) Is(target error) bool {
+	return target == apperror.ErrValidation
+}
+
+// Обмеження полів
+const (
+	MaxNameLength     = 100
+	MaxEmailLength    = 254
+	MinPasswordLength = 8
+	// bcrypt враховує лише перші 72 байти, довші паролі мовчки обрізались би
+	MaxPasswordBytes = 72
+)
+
+// NormalizeEmail - email порівнюються та зберігаються в нижньому регістрі
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Email - повертає опис помилки або "" для коректного (вже нормалізованого) email
+func Email(email string) string {
+	if email == "" {
+		return "email обов'язковий"
+	}
+	if len(email) > MaxEmailLength {
+		return "email занадто довгий"
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "некоректний email"
+	}
+	return ""
+}
+
+// Name - ім'я не порожнє і не довше MaxNameLength символів
+func Name(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return "ім'я обов'язкове"
+	}
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return "ім'я занадто довге"
+	}
+	return ""
+}
+
+// Password - політика паролів для реєстрації, зміни та скидання пароля:
+// від 8 символів, не більше 72 байтів, щонайменше одна літера і одна цифра
+func Password(password string) string {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return "пароль повинен містити щонайменше 8 символів"
+	}
+	if len(password) > MaxPasswordBytes {
+		return "пароль занадто довгий"
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return "пароль повинен містити літери та цифри"
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+-- Початкова схема (таблиці, що вже існують у продакшн-базі)
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS components (
+    id        SERIAL PRIMARY KEY,
+    name      VARCHAR(255) NOT NULL,
+    category  VARCHAR(50)  NOT NULL,
+    price     NUMERIC(10, 2) NOT NULL,
+    image_url TEXT NOT NULL DEFAULT '',
+    specs     JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id               SERIAL PRIMARY KEY,
+    customer_name    VARCHAR(255) NOT NULL,
+    phone            VARCHAR(50)  NOT NULL,
+    delivery_address TEXT NOT NULL,
+    payment_method   VARCHAR(50)  NOT NULL,
+    total_price      NUMERIC(10, 2) NOT NULL,
+    component_ids    JSONB NOT NULL DEFAULT '[]',
+    user_id          INT REFERENCES users (id),
+    status           VARCHAR(50) NOT NULL DEFAULT 'pending',
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
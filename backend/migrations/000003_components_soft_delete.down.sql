@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_components_active_category;
+ALTER TABLE components DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+-- М'яке видалення: компонент ховається з каталогу, але старі замовлення
+-- й надалі можуть отримати його назву за id
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_components_active_category
+    ON components (category) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE components DROP CONSTRAINT IF EXISTS components_supplier_sku_key;
+ALTER TABLE components DROP COLUMN IF EXISTS supplier_sku;
@@ -0,0 +1,6 @@
+-- Артикул постачальника: ключ для upsert під час імпорту прайс-листів
+ALTER TABLE components
+    ADD COLUMN IF NOT EXISTS supplier_sku VARCHAR(100);
+
+ALTER TABLE components
+    ADD CONSTRAINT components_supplier_sku_key UNIQUE (supplier_sku);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS components_price_history ON components;
+DROP FUNCTION IF EXISTS record_price_change();
+DROP TABLE IF EXISTS price_history;
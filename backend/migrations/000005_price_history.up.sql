@@ -0,0 +1,28 @@
+-- Історія цін: тригер пише рядок при створенні компонента і при кожній зміні ціни,
+-- незалежно від того, хто її змінив (адмінка, імпорт прайсу чи ручний SQL)
+CREATE TABLE IF NOT EXISTS price_history (
+    id           BIGSERIAL PRIMARY KEY,
+    component_id INT NOT NULL REFERENCES components (id) ON DELETE CASCADE,
+    price        NUMERIC(10, 2) NOT NULL,
+    changed_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_component_changed
+    ON price_history (component_id, changed_at DESC);
+
+CREATE OR REPLACE FUNCTION record_price_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.price IS DISTINCT FROM OLD.price THEN
+        INSERT INTO price_history (component_id, price) VALUES (NEW.id, NEW.price);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER components_price_history
+    AFTER INSERT OR UPDATE OF price ON components
+    FOR EACH ROW EXECUTE FUNCTION record_price_change();
+
+-- Стартова точка для вже існуючих компонентів
+INSERT INTO price_history (component_id, price)
+SELECT id, price FROM components;
@@ -0,0 +1,29 @@
+-- Відстеження цін: один компонент (kind = 'component') або ціла збірка (kind = 'build')
+CREATE TABLE IF NOT EXISTS watches (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind                VARCHAR(20) NOT NULL CHECK (kind IN ('component', 'build')),
+    name                VARCHAR(255) NOT NULL DEFAULT '',
+    component_ids       INT[] NOT NULL,
+    target_price        NUMERIC(10, 2) NOT NULL,
+    -- Ціна, про яку вже повідомили; NULL - ще не повідомляли (або ціна знову зросла)
+    last_notified_price NUMERIC(10, 2),
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_watches_user ON watches (user_id);
+
+-- Внутрішні сповіщення користувача (показуються в профілі)
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(50) NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    message    TEXT NOT NULL,
+    data       JSONB NOT NULL DEFAULT '{}',
+    read_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+    ON notifications (user_id, created_at DESC);
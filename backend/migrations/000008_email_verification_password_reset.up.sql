@@ -0,0 +1,16 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Одноразові токени підтвердження email та скидання пароля (зберігається лише хеш)
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(30) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose
+    ON user_tokens (user_id, purpose) WHERE used_at IS NULL;
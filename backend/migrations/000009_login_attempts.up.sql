@@ -0,0 +1,7 @@
+-- Лічильники невдалих входів: ключ "email:<адреса>" або "ip:<адреса>"
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key             VARCHAR(320) PRIMARY KEY,
+    failures        INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_until    TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Перейменовані дублікати і регістр адрес не відновлюються - лише індекс
+DROP INDEX IF EXISTS idx_users_email_lower;
@@ -0,0 +1,24 @@
+-- Email тепер зберігаються в нижньому регістрі і унікальні без урахування регістру:
+-- перевірка в сервісі не рятує від паралельних реєстрацій, тож гарантує її індекс.
+--
+-- Акаунти, що відрізняються лише регістром email, уже могли з'явитися. Адресу лишаємо
+-- одному з них (спершу підтвердженому, потім тому, де вона вже в нижньому регістрі,
+-- потім найстарішому), решті - службову адресу з id і знімаємо підтвердження.
+-- Такі акаунти не видаляються: їхні замовлення лишаються, а адресу можна виправити вручну.
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY LOWER(email)
+        ORDER BY email_verified_at IS NULL, email <> LOWER(email), id
+    ) AS rn
+    FROM users
+)
+UPDATE users u
+SET email = LEFT('duplicate-' || u.id || '.' || LOWER(u.email), 255),
+    email_verified_at = NULL
+FROM ranked r
+WHERE r.id = u.id AND r.rn > 1;
+
+UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email);
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
@@ -0,0 +1,13 @@
+-- Зовнішні облікові записи (OpenID Connect), прив'язані до користувачів.
+-- Один користувач може мати кілька провайдерів, але кожен (provider, subject) - лише одного користувача.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         SERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   VARCHAR(50)  NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities (user_id);
@@ -0,0 +1,16 @@
+-- Двофакторна автентифікація (TOTP, RFC 6238).
+-- totp_secret заповнюється при підключенні, а діє лише після підтвердження (totp_enabled_at).
+-- totp_last_step - останній використаний 30-секундний інтервал, щоб код не можна було використати двічі.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret     VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS totp_last_step  BIGINT;
+
+-- Одноразові коди відновлення на випадок втрати телефону (зберігається лише хеш)
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id        BIGSERIAL PRIMARY KEY,
+    user_id   INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at   TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS components_notify_changed ON components;
+DROP FUNCTION IF EXISTS notify_components_changed();
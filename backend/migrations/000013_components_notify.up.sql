@@ -0,0 +1,12 @@
+-- Сповіщення про зміни каталогу для кешу в пам'яті серверів (LISTEN components_changed).
+-- Тригер на рівні оператора: імпорт прайсу на тисячі рядків дає одне сповіщення.
+CREATE OR REPLACE FUNCTION notify_components_changed() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('components_changed', TG_OP);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER components_notify_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON components
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_components_changed();
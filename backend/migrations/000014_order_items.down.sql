@@ -0,0 +1,2 @@
+-- orders.component_ids весь час заповнювався разом з order_items - переносити назад нічого
+DROP TABLE IF EXISTS order_items;
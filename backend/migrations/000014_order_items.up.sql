@@ -0,0 +1,26 @@
+-- Склад замовлення окремою таблицею замість JSON-масиву в orders:
+-- список замовлень разом з назвами компонентів - один запит з JOIN.
+--
+-- Це перший крок (expand): orders.component_ids лишається, і CreateOrder далі його
+-- заповнює, тож інстанси попередньої версії працюють під час розгортання і після відкату.
+-- Колонку прибере окрема міграція (contract), коли старих інстансів не лишиться;
+-- перед видаленням вона має ще раз перенести склад замовлень без рядків в order_items -
+-- їх могли створити старі інстанси вже після цієї міграції.
+--
+-- Зовнішнього ключа на components немає: раніше id не перевірялись, а склад старих
+-- замовлень переносимо повністю. Id нових замовлень перевіряє CreateOrder.
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id     INT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position     INT NOT NULL,
+    component_id INT NOT NULL,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_component ON order_items (component_id);
+
+INSERT INTO order_items (order_id, position, component_id)
+SELECT o.id, e.position, e.component_id::int
+FROM orders o
+CROSS JOIN LATERAL jsonb_array_elements_text(o.component_ids) WITH ORDINALITY AS e(component_id, position)
+WHERE jsonb_typeof(o.component_ids) = 'array'
+ON CONFLICT DO NOTHING;
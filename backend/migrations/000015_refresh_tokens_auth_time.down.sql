@@ -0,0 +1,2 @@
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS auth_time;
@@ -0,0 +1,5 @@
+-- Коли користувач востаннє справді автентифікувався (пароль, OIDC, 2FA) у цій сесії.
+-- Переходить до нових токенів при ротації, щоб небезпечні дії вимагали свіжого входу.
+-- NULL - сесії, відкриті до міграції: для них свіжого входу немає.
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS auth_time TIMESTAMP;
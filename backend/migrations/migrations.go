@@ -0,0 +1,62 @@
+// Package migrations - SQL-міграції схеми.
+// Файли вбудовано в бінарник, щоб сервер знав, якої версії схеми він очікує,
+// і міг сам їх застосувати: `server migrate` (наприклад, як pre-deploy команда).
+// Версію ведемо в таблиці schema_migrations у форматі golang-migrate,
+// тож базу можна й далі обслуговувати його CLI.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// migration - один up-файл
+type migration struct {
+	version int
+	name    string
+}
+
+// upMigrations - up-міграції за зростанням версії (з імен файлів виду 000012_two_factor_auth.up.sql)
+func upMigrations() ([]migration, error) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]migration, 0, len(names))
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+		v, err := strconv.Atoi(prefix)
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("некоректне ім'я міграції %q", name)
+		}
+		list = append(list, migration{version: v, name: name})
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("не знайдено жодної міграції")
+	}
+
+	slices.SortFunc(list, func(a, b migration) int { return a.version - b.version })
+	for i := 1; i < len(list); i++ {
+		if list[i].version == list[i-1].version {
+			return nil, fmt.Errorf("дві міграції з версією %d", list[i].version)
+		}
+	}
+	return list, nil
+}
+
+// Latest - номер останньої міграції
+func Latest() (int, error) {
+	list, err := upMigrations()
+	if err != nil {
+		return 0, err
+	}
+	return list[len(list)-1].version, nil
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrateLockID - ключ pg_advisory_lock: кілька інстансів, запущених одночасно,
+// застосовують міграції по черзі, а не паралельно
+const migrateLockID = 7_351_208_114
+
+// Up - застосовує всі міграції, новіші за поточну версію схеми, і повертає їхні номери.
+// Як і golang-migrate, перед міграцією позначає версію dirty і знімає позначку після успіху:
+// якщо міграція впала посередині, наступні запуски відмовляться працювати, доки схему
+// не виправлять вручну.
+func Up(ctx context.Context, db *sql.DB) ([]int, error) {
+	list, err := upMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Блокування сесійне, тож усе робимо в одному з'єднанні
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrateLockID); err != nil {
+		return nil, fmt.Errorf("не вдалося заблокувати міграції: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockID)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty   BOOLEAN NOT NULL
+		)`); err != nil {
+		return nil, fmt.Errorf("не вдалося створити schema_migrations: %w", err)
+	}
+
+	var current int
+	var dirty bool
+	err = conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&current, &dirty)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("помилка читання версії схеми: %w", err)
+	}
+	if dirty {
+		return nil, fmt.Errorf("міграція %d застосована не повністю (dirty), виправте схему вручну", current)
+	}
+
+	var applied []int
+	for _, m := range list {
+		if m.version <= current {
+			continue
+		}
+
+		body, err := FS.ReadFile(m.name)
+		if err != nil {
+			return applied, err
+		}
+
+		if err := setVersion(ctx, conn, m.version, true); err != nil {
+			return applied, err
+		}
+		// Без параметрів lib/pq шле файл як simple query - кілька команд за раз дозволено
+		if _, err := conn.ExecContext(ctx, string(body)); err != nil {
+			return applied, fmt.Errorf("міграція %s: %w", m.name, err)
+		}
+		if err := setVersion(ctx, conn, m.version, false); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m.version)
+	}
+
+	return applied, nil
+}
+
+// setVersion - schema_migrations завжди містить один рядок, як у golang-migrate
+func setVersion(ctx context.Context, conn *sql.Conn, version int, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return fmt.Errorf("не вдалося оновити версію схеми: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+		return fmt.Errorf("не вдалося оновити версію схеми: %w", err)
+	}
+	return tx.Commit()
+}
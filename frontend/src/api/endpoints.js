@@ -16,6 +16,8 @@ export const endpoints = {
     auth: {
         login: '/auth/sign-in',
         register: '/auth/sign-up',
+        refresh: '/auth/refresh',
+        logout: '/auth/logout',
         profile: '/auth/me',
         changePassword: '/auth/change-password',
     },
@@ -43,3 +45,56 @@ api.interceptors.request.use(
     },
     (error) => Promise.reject(error)
 );
+
+// Access-токен живе 15 хвилин: на 401 один раз оновлюємо пару токенів
+// через refresh-токен і повторюємо запит. Паралельні запити чекають на те саме оновлення,
+// бо refresh-токен одноразовий (ротація на сервері).
+let refreshPromise = null;
+
+const refreshTokens = () => {
+    if (!refreshPromise) {
+        const refreshToken = localStorage.getItem('refresh_token');
+        refreshPromise = (refreshToken
+            ? api.post(endpoints.auth.refresh, { refresh_token: refreshToken }, { _skipRefresh: true })
+            : Promise.reject(new Error('no refresh token'))
+        )
+            .then((response) => {
+                localStorage.setItem('token', response.data.token);
+                localStorage.setItem('refresh_token', response.data.refresh_token);
+                return response.data.token;
+            })
+            .catch((error) => {
+                // Сесію відкликано або вона закінчилась - AuthContext розлогінює користувача
+                localStorage.removeItem('token');
+                localStorage.removeItem('refresh_token');
+                window.dispatchEvent(new Event('auth:logout'));
+                throw error;
+            })
+            .finally(() => {
+                refreshPromise = null;
+            });
+    }
+    return refreshPromise;
+};
+
+api.interceptors.response.use(
+    (response) => response,
+    async (error) => {
+        const original = error.config;
+        if (error.response?.status !== 401 || !original || original._skipRefresh || original._retried) {
+            return Promise.reject(error);
+        }
+        // Невдалий вхід теж дає 401 - це не прострочений токен
+        if (!original.headers?.Authorization) {
+            return Promise.reject(error);
+        }
+
+        original._retried = true;
+        const token = await refreshTokens().catch(() => null);
+        if (!token) {
+            return Promise.reject(error);
+        }
+        original.headers.Authorization = `Bearer ${token}`;
+        return api(original);
+    }
+);
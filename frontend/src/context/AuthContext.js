@@ -30,34 +30,52 @@ export const AuthProvider = ({ children }) => {
     useEffect(() => {
         const token = localStorage.getItem('token');
         const savedName = localStorage.getItem('username');
+        // Прострочений access-токен не біда, якщо є refresh-токен: api оновить його на першому 401
+        const refreshToken = localStorage.getItem('refresh_token');
 
-        if (token && isTokenValid(token)) {
+        if (token && (isTokenValid(token) || refreshToken)) {
             setIsAuthenticated(true);
             setUser({ name: savedName || 'User' });
         } else {
             // Якщо токен невалідний або його немає — чистимо локалсторедж
             localStorage.removeItem('token');
+            localStorage.removeItem('refresh_token');
             localStorage.removeItem('username');
             setIsAuthenticated(false);
             setUser(null);
         }
 
         setLoading(false);
+
+        // api не зміг оновити токени - сесія завершена
+        const onLogout = () => {
+            localStorage.removeItem('username');
+            setIsAuthenticated(false);
+            setUser(null);
+        };
+        window.addEventListener('auth:logout', onLogout);
+        return () => window.removeEventListener('auth:logout', onLogout);
     }, []);
 
+    // Нова пара токенів від сервера (напр. після зміни пароля, коли старі сесії відкликано)
+    const saveTokens = (token, refreshToken) => {
+        localStorage.setItem('token', token);
+        localStorage.setItem('refresh_token', refreshToken);
+        setIsAuthenticated(true);
+    };
+
     // Функція ВХОДУ (Login)
     const login = async (email, password) => {
         try {
             const response = await api.post(endpoints.auth.login, { email, password });
             
-            // Сервер повертає { token: "..." }
-            const { token } = response.data;
+            // Сервер повертає { token: "...", refresh_token: "..." }
+            const { token, refresh_token } = response.data;
             
-            // Зберігаємо токен
-            localStorage.setItem('token', token);
+            // Зберігаємо токени
+            saveTokens(token, refresh_token);
             localStorage.setItem('username', email.split('@')[0]); // Тимчасово беремо ім'я з пошти
             
-            setIsAuthenticated(true);
             setUser({ name: email.split('@')[0] });
             return { success: true };
         } catch (error) {
@@ -80,14 +98,20 @@ export const AuthProvider = ({ children }) => {
 
     // Функція ВИХОДУ
     const logout = () => {
+        // Відкликаємо сесію на сервері; результат не важливий - локально виходимо в будь-якому разі
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (refreshToken) {
+            api.post(endpoints.auth.logout, { refresh_token: refreshToken }, { _skipRefresh: true }).catch(() => {});
+        }
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         localStorage.removeItem('username');
         setIsAuthenticated(false);
         setUser(null);
     };
 
     return (
-        <AuthContext.Provider value={{ user, isAuthenticated, login, register, logout, loading }}>
+        <AuthContext.Provider value={{ user, isAuthenticated, login, register, logout, saveTokens, loading }}>
             {!loading && children}
         </AuthContext.Provider>
     );
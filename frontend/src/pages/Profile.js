@@ -3,7 +3,7 @@ import { useAuth } from '../context/AuthContext';
 import { api } from '../api/endpoints';
 
 const Profile = () => {
-    const { user } = useAuth();
+    const { user, saveTokens } = useAuth();
     
     const [activeTab, setActiveTab] = useState('info');
     const [isLoading, setIsLoading] = useState(false);
@@ -102,17 +102,20 @@ const Profile = () => {
             setError('Новий пароль не співпадає з підтвердженням');
             return;
         }
-        if (passwordForm.newPassword.length < 6) {
-            setError('Новий пароль має бути мінімум 6 символів');
+        // Та сама межа, що й validation.MinPasswordLength на сервері
+        if (passwordForm.newPassword.length < 8) {
+            setError('Новий пароль має бути мінімум 8 символів');
             return;
         }
 
         setIsLoading(true);
         try {
-            await api.post('/auth/change-password', {
+            const response = await api.post('/auth/change-password', {
                 current_password: passwordForm.currentPassword,
                 new_password: passwordForm.newPassword
             });
+            // Сервер відкликав усі refresh-токени, включно з нашим, і видав нову пару
+            saveTokens(response.data.token, response.data.refresh_token);
             setMessage('✅ Пароль успішно змінено!');
             setPasswordForm({ currentPassword: '', newPassword: '', confirmPassword: '' });
         } catch (err) {